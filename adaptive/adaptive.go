@@ -0,0 +1,161 @@
+// Package adaptive throttles requests on the client side, rejecting a share of them that grows as the
+// share accepted by the dependency falls, as described in the Google SRE book.
+package adaptive
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+type ThrottleError string
+
+func (e ThrottleError) Error() string {
+	return string(e)
+}
+
+// Is reports Throttled as a gocircuit.ErrOpen, so that retries give up on it.
+func (e ThrottleError) Is(target error) bool {
+	return e == Throttled && target == gocircuit.ErrOpen
+}
+
+const Throttled ThrottleError = "request throttled"
+
+// RejectionProbability is max(0, (requests - k*accepts) / (requests + 1)).
+func RejectionProbability(requests int64, accepts int64, k float64) float64 {
+	return max(0, (float64(requests)-k*float64(accepts))/float64(requests+1))
+}
+
+// Settings configures a throttle. Zero values select the defaults.
+type Settings struct {
+	Window  time.Duration // The period of time over which requests and accepts are counted. Defaults to 2 minutes.
+	Buckets int           // The number of buckets the window is divided into. Defaults to 10.
+	// K is how many requests are allowed per accept before any are rejected. Lower is more aggressive. Defaults to 2.
+	K float64
+	// IsSuccessful decides whether an error counts as accepted by the dependency. A caller canceling the context is counted as neither.
+	IsSuccessful func(err error) bool
+
+	Rand func() float64 // The source of rejection decisions in [0, 1). Defaults to math/rand/v2.
+}
+
+// WithDefaults returns settings with zero values replaced by the defaults.
+func (s Settings) WithDefaults() Settings {
+	if s.Window <= 0 {
+		s.Window = 2 * time.Minute
+	}
+	if s.Buckets <= 0 {
+		s.Buckets = 10
+	}
+	if s.K <= 0 {
+		s.K = 2
+	}
+	if s.Rand == nil {
+		s.Rand = rand.Float64
+	}
+	return s
+}
+
+type bucket struct {
+	index    int64 // The number of bucket widths since the epoch this bucket counts.
+	requests int64
+	accepts  int64
+}
+
+type throttle[A any] struct {
+	name     string
+	settings Settings
+	width    int64
+
+	mu      sync.Mutex
+	buckets []bucket
+	events  *gocircuit.EventBus
+}
+
+// NewAdaptiveThrottle creates an in-memory throttle named name, rejecting requests with Throttled.
+func NewAdaptiveThrottle[A any](name string, settings Settings) gocircuit.CircuitBreaker[A] {
+	settings = settings.WithDefaults()
+	return &throttle[A]{
+		name:     name,
+		settings: settings,
+		width:    max(1, settings.Window.Nanoseconds()/int64(settings.Buckets)),
+		buckets:  make([]bucket, settings.Buckets),
+		events:   &gocircuit.EventBus{},
+	}
+}
+
+func (t *throttle[A]) Protect(ctx context.Context, action func() (A, error)) (A, error) {
+	t.mu.Lock()
+	requests, accepts := t.counts(time.Now())
+	reject := t.settings.Rand() < RejectionProbability(requests, accepts, t.settings.K)
+	if reject {
+		t.record(time.Now(), gocircuit.OutcomeFailure)
+	}
+	t.mu.Unlock()
+	if reject {
+		t.events.Publish(gocircuit.Event{Kind: gocircuit.EventRejected, Policy: gocircuit.PolicyAdaptive, Breaker: t.name, Err: Throttled})
+		var empty A
+		return empty, Throttled
+	}
+
+	value, err, outcome := Run(ctx, t.name, t.settings, t.events, action)
+	t.mu.Lock()
+	t.record(time.Now(), outcome)
+	t.mu.Unlock()
+	return value, err
+}
+
+// counts returns the requests and accepts in the window ending at now.
+func (t *throttle[A]) counts(now time.Time) (int64, int64) {
+	current := now.UnixNano() / t.width
+	var requests, accepts int64
+	for _, b := range t.buckets {
+		if current-b.index < int64(len(t.buckets)) {
+			requests += b.requests
+			accepts += b.accepts
+		}
+	}
+	return requests, accepts
+}
+
+func (t *throttle[A]) record(now time.Time, outcome gocircuit.Outcome) {
+	if outcome == gocircuit.OutcomeIgnore {
+		return
+	}
+	index := now.UnixNano() / t.width
+	b := &t.buckets[index%int64(len(t.buckets))]
+	if b.index != index {
+		*b = bucket{index: index}
+	}
+	b.requests++
+	if outcome == gocircuit.OutcomeSuccess {
+		b.accepts++
+	}
+}
+
+// Probability returns the current probability of rejecting a request.
+func (t *throttle[A]) Probability() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	requests, accepts := t.counts(time.Now())
+	return RejectionProbability(requests, accepts, t.settings.K)
+}
+
+// Subscribe returns a subscription to the activity of the throttle, buffering up to buffer events.
+func (t *throttle[A]) Subscribe(buffer int) *gocircuit.Subscription {
+	return t.events.Subscribe(buffer)
+}
+
+// Run runs an action admitted by a throttle named name, publishing its outcome to events.
+func Run[A any](ctx context.Context, name string, settings Settings, events *gocircuit.EventBus, action func() (A, error)) (A, error, gocircuit.Outcome) {
+	events.Publish(gocircuit.Event{Kind: gocircuit.EventAdmitted, Policy: gocircuit.PolicyAdaptive, Breaker: name})
+	start := time.Now()
+	value, err := action()
+	outcome := gocircuit.Classify(ctx, nil, settings.IsSuccessful, value, err)
+	gocircuit.ObserveOutcome(ctx, outcome)
+	kind := gocircuit.EventKindOf(outcome)
+	events.Publish(gocircuit.Event{Kind: kind, Policy: gocircuit.PolicyAdaptive, Breaker: name, Err: err, Duration: time.Since(start)})
+	return value, err, outcome
+}
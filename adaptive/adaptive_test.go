@@ -0,0 +1,53 @@
+package adaptive
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+func TestRejectionProbability(t *testing.T) {
+	for _, c := range []struct {
+		requests, accepts int64
+		want              float64
+	}{
+		{0, 0, 0},
+		{10, 10, 0},
+		{10, 5, 0},
+		{9, 0, 0.9},
+		{9, 3, 0.3},
+	} {
+		if got := RejectionProbability(c.requests, c.accepts, 2); got != c.want {
+			t.Errorf("RejectionProbability(%d, %d, 2) = %g, want %g", c.requests, c.accepts, got, c.want)
+		}
+	}
+}
+
+func TestThrottlesFailingDependency(t *testing.T) {
+	ctx := context.Background()
+	failed := errors.New("failed")
+	th := NewAdaptiveThrottle[int]("test", Settings{Rand: func() float64 { return 0.5 }})
+	for i := 0; i < 2; i++ { // Rejection probabilities 0 and 1/2, which 0.5 does not fall below.
+		if _, err := th.Protect(ctx, func() (int, error) { return 0, failed }); err != failed {
+			t.Fatalf("call %d: expected the failure, got %v", i, err)
+		}
+	}
+	_, err := th.Protect(ctx, func() (int, error) { return 1, nil }) // Rejected with probability 2/3.
+	if err != Throttled || !errors.Is(err, gocircuit.ErrOpen) {
+		t.Errorf("expected Throttled matching gocircuit.ErrOpen, got %v", err)
+	}
+}
+
+func TestIgnoresCallerCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	th := NewAdaptiveThrottle[int]("test", Settings{})
+	for i := 0; i < 10; i++ {
+		_, _ = th.Protect(ctx, func() (int, error) { return 0, ctx.Err() })
+	}
+	if p := th.(interface{ Probability() float64 }).Probability(); p != 0 {
+		t.Errorf("expected canceled calls not to count, got a rejection probability of %g", p)
+	}
+}
@@ -0,0 +1,203 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+var (
+	errNotFound     = errors.New("circuit breaker not found")
+	errNotSupported = errors.New("circuit breaker does not support this operation")
+)
+
+type breakerStatus struct {
+	Name        string             `json:"name"`
+	Inspectable bool               `json:"inspectable"`
+	State       *gocircuit.State   `json:"state,omitempty"`
+	Counts      *counts            `json:"counts,omitempty"`
+	OpenUntil   *time.Time         `json:"openUntil,omitempty"`
+	Transitions []transitionStatus `json:"transitions,omitempty"`
+	Error       string             `json:"error,omitempty"`
+}
+
+type counts struct {
+	Requests             int64 `json:"requests"`
+	TotalSuccesses       int64 `json:"totalSuccesses"`
+	TotalFailures        int64 `json:"totalFailures"`
+	ConsecutiveSuccesses int64 `json:"consecutiveSuccesses"`
+	ConsecutiveFailures  int64 `json:"consecutiveFailures"`
+
+	Weighted weightedCounts `json:"weighted"`
+}
+
+type weightedCounts struct {
+	Requests             float64 `json:"requests"`
+	TotalSuccesses       float64 `json:"totalSuccesses"`
+	TotalFailures        float64 `json:"totalFailures"`
+	ConsecutiveSuccesses float64 `json:"consecutiveSuccesses"`
+	ConsecutiveFailures  float64 `json:"consecutiveFailures"`
+}
+
+type transitionStatus struct {
+	Id       string          `json:"id,omitempty"`
+	From     gocircuit.State `json:"from"`
+	To       gocircuit.State `json:"to"`
+	Counts   counts          `json:"counts"`
+	Reason   string          `json:"reason,omitempty"`
+	Instance string          `json:"instance,omitempty"`
+	Time     time.Time       `json:"time"`
+}
+
+// NewHandler returns an http.Handler serving the breakers in registry:
+//
+//	GET  /                 lists every breaker
+//	GET  /{name}           shows a single breaker
+//	POST /{name}/open      forces the breaker open
+//	POST /{name}/close     forces the breaker closed
+//	POST /{name}/reset     lifts any forced state and resets the breaker
+//
+// The open and close endpoints accept an optional expiry query parameter
+// such as ?expiry=10m. The handler serves paths relative to its root, so
+// mount it with http.StripPrefix, for example
+//
+//	mux.Handle("/debug/breakers/", http.StripPrefix("/debug/breakers", admin.NewHandler(registry)))
+func NewHandler(registry *Registry) http.Handler {
+	h := &handler{registry: registry}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", h.list)
+	mux.HandleFunc("GET /{name}", h.show)
+	mux.HandleFunc("POST /{name}/open", h.force(gocircuit.StateForcedOpen))
+	mux.HandleFunc("POST /{name}/close", h.force(gocircuit.StateForcedClosed))
+	mux.HandleFunc("POST /{name}/reset", h.reset)
+	return mux
+}
+
+type handler struct {
+	registry *Registry
+}
+
+func (h *handler) list(w http.ResponseWriter, r *http.Request) {
+	names := h.registry.Names()
+	statuses := make([]breakerStatus, 0, len(names))
+	for _, name := range names {
+		breaker, ok := h.registry.Get(name)
+		if !ok { // Unregistered since Names was called.
+			continue
+		}
+		statuses = append(statuses, status(r, name, breaker))
+	}
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+func (h *handler) show(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	breaker, ok := h.registry.Get(name)
+	if !ok {
+		writeError(w, http.StatusNotFound, errNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, status(r, name, breaker))
+}
+
+func (h *handler) force(state gocircuit.State) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var expiry time.Duration
+		if expiryString := r.URL.Query().Get("expiry"); expiryString != "" {
+			var err error
+			expiry, err = time.ParseDuration(expiryString)
+			if err != nil || expiry < 0 {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("invalid expiry: %s", expiryString))
+				return
+			}
+		}
+		h.control(w, r, func(controller gocircuit.Controller) error {
+			return controller.Force(r.Context(), state, expiry)
+		})
+	}
+}
+
+func (h *handler) reset(w http.ResponseWriter, r *http.Request) {
+	h.control(w, r, func(controller gocircuit.Controller) error {
+		return controller.Reset(r.Context())
+	})
+}
+
+func (h *handler) control(w http.ResponseWriter, r *http.Request, f func(controller gocircuit.Controller) error) {
+	name := r.PathValue("name")
+	breaker, ok := h.registry.Get(name)
+	if !ok {
+		writeError(w, http.StatusNotFound, errNotFound)
+		return
+	}
+	controller, ok := gocircuit.AsController(breaker)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, errNotSupported)
+		return
+	}
+	if err := f(controller); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, status(r, name, breaker))
+}
+
+func status(r *http.Request, name string, breaker any) breakerStatus {
+	inspector, ok := gocircuit.AsInspector(breaker)
+	if !ok {
+		return breakerStatus{Name: name}
+	}
+	snapshot, err := inspector.Inspect(r.Context())
+	if err != nil {
+		return breakerStatus{Name: name, Inspectable: true, Error: err.Error()}
+	}
+
+	s := breakerStatus{
+		Name:        name,
+		Inspectable: true,
+		State:       &snapshot.State,
+		Counts:      countsFrom(snapshot.Counts),
+	}
+	if !snapshot.OpenUntil.IsZero() {
+		s.OpenUntil = &snapshot.OpenUntil
+	}
+	for _, t := range snapshot.Transitions {
+		s.Transitions = append(s.Transitions, transitionStatus{
+			Id:       t.Id,
+			From:     t.From,
+			To:       t.To,
+			Counts:   *countsFrom(t.Counts),
+			Reason:   t.Reason,
+			Instance: t.Instance,
+			Time:     t.Time,
+		})
+	}
+	return s
+}
+
+func countsFrom(c gocircuit.Counts) *counts {
+	return &counts{
+		Requests:             c.Requests,
+		TotalSuccesses:       c.TotalSuccesses,
+		TotalFailures:        c.TotalFailures,
+		ConsecutiveSuccesses: c.ConsecutiveSuccesses,
+		ConsecutiveFailures:  c.ConsecutiveFailures,
+		Weighted:             weightedCounts(c.Weighted),
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, struct {
+		Error string `json:"error"`
+	}{Error: err.Error()})
+}
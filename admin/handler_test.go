@@ -0,0 +1,117 @@
+package admin
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+	"github.com/christopherdavenport/gocircuit/memory"
+	"github.com/christopherdavenport/gocircuit/noop"
+	"github.com/christopherdavenport/gocircuit/slogcircuit"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	registry := NewRegistry()
+	breaker := memory.NewMemoryCircuitBreaker[int]("memory", memory.Settings{})
+	registry.Register("memory", slogcircuit.New("memory", breaker, slog.New(slog.NewTextHandler(io.Discard, nil)))) // Found through the decorator.
+	registry.Register("noop", noop.NewNoopCircuitBreaker[int]("noop"))
+	server := httptest.NewServer(NewHandler(registry))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// do makes a request to server, checks its status code, and decodes its body into v.
+func do(t *testing.T, server *httptest.Server, method string, path string, code int, v any) {
+	t.Helper()
+	request, err := http.NewRequest(method, server.URL+path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != code {
+		t.Fatalf("%s %s: expected %d, got %d", method, path, code, response.StatusCode)
+	}
+	if err := json.NewDecoder(response.Body).Decode(v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	server := newTestServer(t)
+
+	var statuses []breakerStatus
+	do(t, server, http.MethodGet, "/", http.StatusOK, &statuses)
+	if len(statuses) != 2 || statuses[0].Name != "memory" || statuses[1].Name != "noop" {
+		t.Fatalf("expected both breakers sorted by name, got %+v", statuses)
+	}
+	if !statuses[0].Inspectable || statuses[0].State == nil || *statuses[0].State != gocircuit.StateClosed {
+		t.Errorf("expected the memory breaker inspected closed, got %+v", statuses[0])
+	}
+	if statuses[1].Inspectable || statuses[1].State != nil {
+		t.Errorf("expected the noop breaker not inspectable, got %+v", statuses[1])
+	}
+
+	var status breakerStatus
+	do(t, server, http.MethodGet, "/memory", http.StatusOK, &status)
+	if status.Name != "memory" || status.Counts == nil {
+		t.Errorf("expected the memory breaker with its counts, got %+v", status)
+	}
+	do(t, server, http.MethodGet, "/missing", http.StatusNotFound, &status)
+}
+
+func TestForceAndReset(t *testing.T) {
+	server := newTestServer(t)
+
+	var status breakerStatus
+	do(t, server, http.MethodPost, "/memory/open?expiry=10m", http.StatusOK, &status)
+	if status.State == nil || *status.State != gocircuit.StateForcedOpen {
+		t.Fatalf("expected the breaker forced open, got %+v", status)
+	}
+	if status.OpenUntil == nil || time.Until(*status.OpenUntil) < 9*time.Minute {
+		t.Errorf("expected the forced state to expire in 10m, got %v", status.OpenUntil)
+	}
+	if len(status.Transitions) != 1 || status.Transitions[0].Reason != gocircuit.ReasonForced {
+		t.Errorf("expected the forced transition, got %+v", status.Transitions)
+	}
+
+	do(t, server, http.MethodPost, "/memory/close", http.StatusOK, &status)
+	if status.State == nil || *status.State != gocircuit.StateForcedClosed {
+		t.Fatalf("expected the breaker forced closed, got %+v", status)
+	}
+
+	do(t, server, http.MethodPost, "/memory/reset", http.StatusOK, &status)
+	if status.State == nil || *status.State != gocircuit.StateClosed {
+		t.Fatalf("expected the breaker reset to closed, got %+v", status)
+	}
+	if len(status.Transitions) != 3 || status.Transitions[0].Reason != gocircuit.ReasonReset {
+		t.Errorf("expected the reset transition newest, got %+v", status.Transitions)
+	}
+}
+
+func TestControlErrors(t *testing.T) {
+	server := newTestServer(t)
+
+	var body struct {
+		Error string `json:"error"`
+	}
+	do(t, server, http.MethodPost, "/memory/open?expiry=soon", http.StatusBadRequest, &body)
+	do(t, server, http.MethodPost, "/memory/open?expiry=-1m", http.StatusBadRequest, &body)
+	do(t, server, http.MethodPost, "/noop/open", http.StatusNotImplemented, &body)
+	if body.Error != errNotSupported.Error() {
+		t.Errorf("expected %q, got %q", errNotSupported, body.Error)
+	}
+	do(t, server, http.MethodPost, "/missing/reset", http.StatusNotFound, &body)
+	if body.Error != errNotFound.Error() {
+		t.Errorf("expected %q, got %q", errNotFound, body.Error)
+	}
+}
@@ -0,0 +1,53 @@
+// Admin exposes circuit breakers over HTTP for inspection and control.
+
+package admin
+
+import (
+	"sort"
+	"sync"
+)
+
+// Registry holds named circuit breakers. Breakers are stored as any since
+// gocircuit.CircuitBreaker is generic; the handler only relies on the
+// gocircuit.Inspector and gocircuit.Controller interfaces, which are also
+// found through decorators implementing gocircuit.Wrapper.
+type Registry struct {
+	mu       sync.RWMutex
+	breakers map[string]any
+}
+
+func NewRegistry() *Registry {
+	return &Registry{breakers: map[string]any{}}
+}
+
+// Register adds breaker under name, replacing any breaker already registered with that name.
+func (r *Registry) Register(name string, breaker any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.breakers[name] = breaker
+}
+
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.breakers, name)
+}
+
+func (r *Registry) Get(name string) (any, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	breaker, ok := r.breakers[name]
+	return breaker, ok
+}
+
+// Names returns the names of all registered breakers in sorted order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.breakers))
+	for name := range r.breakers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
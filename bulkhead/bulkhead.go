@@ -0,0 +1,100 @@
+// Package bulkhead limits the number of actions in flight at once.
+//
+// A bulkhead implements gocircuit.CircuitBreaker[A], so it stacks with a circuit breaker by protecting
+// the call to the breaker, or the other way around:
+//
+//	bh.Protect(ctx, func() (A, error) { return cb.Protect(ctx, action) })
+package bulkhead
+
+import (
+	"context"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+type BulkheadError string
+
+func (e BulkheadError) Error() string {
+	return string(e)
+}
+
+const BulkheadFull BulkheadError = "bulkhead is full"
+
+// Settings configures a bulkhead created by NewBulkhead.
+type Settings struct {
+	MaxConcurrent int // The number of actions allowed in flight at once. Must be positive.
+	// MaxWaiting is the number of callers allowed to wait for a slot once MaxConcurrent are in flight,
+	// each until its context is done. Zero rejects immediately when full.
+	MaxWaiting int
+}
+
+type bulkhead[A any] struct {
+	name    string
+	slots   chan struct{}
+	waiting chan struct{}
+	events  *gocircuit.EventBus
+}
+
+// NewBulkhead creates an in-memory bulkhead named name.
+func NewBulkhead[A any](name string, settings Settings) gocircuit.CircuitBreaker[A] {
+	if settings.MaxConcurrent <= 0 {
+		panic("bulkhead: MaxConcurrent must be positive")
+	}
+	return &bulkhead[A]{
+		name:    name,
+		slots:   make(chan struct{}, settings.MaxConcurrent),
+		waiting: make(chan struct{}, settings.MaxWaiting),
+		events:  &gocircuit.EventBus{},
+	}
+}
+
+func (b *bulkhead[A]) Protect(ctx context.Context, action func() (A, error)) (A, error) {
+	if err := b.acquire(ctx); err != nil {
+		b.events.Publish(gocircuit.Event{Kind: gocircuit.EventRejected, Policy: gocircuit.PolicyBulkhead, Breaker: b.name, Err: err})
+		var empty A
+		return empty, err
+	}
+	defer func() { <-b.slots }()
+	return Run(ctx, b.name, b.events, action)
+}
+
+func (b *bulkhead[A]) acquire(ctx context.Context) error {
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	default:
+	}
+	select {
+	case b.waiting <- struct{}{}:
+	default:
+		return BulkheadFull
+	}
+	defer func() { <-b.waiting }()
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// InFlight returns the number of actions currently running.
+func (b *bulkhead[A]) InFlight() int {
+	return len(b.slots)
+}
+
+// Subscribe returns a subscription to the activity of the bulkhead, buffering up to buffer events.
+func (b *bulkhead[A]) Subscribe(buffer int) *gocircuit.Subscription {
+	return b.events.Subscribe(buffer)
+}
+
+// Run runs an action admitted by a bulkhead named name, publishing its outcome to events.
+func Run[A any](ctx context.Context, name string, events *gocircuit.EventBus, action func() (A, error)) (A, error) {
+	events.Publish(gocircuit.Event{Kind: gocircuit.EventAdmitted, Policy: gocircuit.PolicyBulkhead, Breaker: name})
+	start := time.Now()
+	value, err := action()
+	kind := gocircuit.EventKindOf(gocircuit.Classify(ctx, nil, nil, value, err))
+	events.Publish(gocircuit.Event{Kind: kind, Policy: gocircuit.PolicyBulkhead, Breaker: name, Err: err, Duration: time.Since(start)})
+	return value, err
+}
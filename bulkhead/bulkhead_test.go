@@ -0,0 +1,74 @@
+package bulkhead
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+// hold runs an action in b until release is closed, returning once it is running.
+func hold(b gocircuit.CircuitBreaker[int], release chan struct{}) {
+	running := make(chan struct{})
+	go func() {
+		_, _ = b.Protect(context.Background(), func() (int, error) {
+			close(running)
+			<-release
+			return 0, nil
+		})
+	}()
+	<-running
+}
+
+func TestRejectsWhenFull(t *testing.T) {
+	b := NewBulkhead[int]("test", Settings{MaxConcurrent: 1})
+	release := make(chan struct{})
+	hold(b, release)
+	defer close(release)
+
+	if _, err := b.Protect(context.Background(), func() (int, error) { return 0, nil }); err != BulkheadFull {
+		t.Errorf("expected BulkheadFull, got %v", err)
+	}
+	if n := b.(*bulkhead[int]).InFlight(); n != 1 {
+		t.Errorf("expected 1 action in flight, got %d", n)
+	}
+}
+
+func TestWaitsUntilContextDone(t *testing.T) {
+	b := NewBulkhead[int]("test", Settings{MaxConcurrent: 1, MaxWaiting: 1})
+	release := make(chan struct{})
+	hold(b, release)
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := b.Protect(ctx, func() (int, error) { return 0, nil }); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the wait to end with the context, got %v", err)
+	}
+}
+
+func TestWaiterTakesFreedSlot(t *testing.T) {
+	b := NewBulkhead[int]("test", Settings{MaxConcurrent: 1, MaxWaiting: 1})
+	release := make(chan struct{})
+	hold(b, release)
+
+	time.AfterFunc(10*time.Millisecond, func() { close(release) })
+	if _, err := b.Protect(context.Background(), func() (int, error) { return 0, nil }); err != nil {
+		t.Errorf("expected the waiter to run once the slot is freed, got %v", err)
+	}
+}
+
+func TestTooManyWaiters(t *testing.T) {
+	b := NewBulkhead[int]("test", Settings{MaxConcurrent: 1})
+	release := make(chan struct{})
+	hold(b, release)
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := b.Protect(ctx, func() (int, error) { return 0, nil }); err != BulkheadFull {
+		t.Errorf("expected BulkheadFull without room to wait, got %v", err)
+	}
+}
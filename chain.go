@@ -0,0 +1,85 @@
+package gocircuit
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// ContextCircuitBreaker is implemented by policies passing a context of their own to the action,
+// such as one with a deadline.
+type ContextCircuitBreaker[A any] interface {
+	CircuitBreaker[A]
+	ProtectContext(ctx context.Context, action func(ctx context.Context) (A, error)) (A, error)
+}
+
+// ProtectContext runs action protected by breaker, with the context breaker passes it if it is a
+// ContextCircuitBreaker, and with ctx otherwise.
+func ProtectContext[A any](breaker CircuitBreaker[A], ctx context.Context, action func(ctx context.Context) (A, error)) (A, error) {
+	if c, ok := breaker.(ContextCircuitBreaker[A]); ok {
+		return c.ProtectContext(ctx, action)
+	}
+	return breaker.Protect(ctx, func() (A, error) {
+		return action(ctx)
+	})
+}
+
+// ProtectObserved is ProtectContext also reporting whether breaker rejected the request, returning an error
+// without running the action. A *TimeoutError is not a rejection, as it may be returned before the abandoned
+// action has started.
+func ProtectObserved[A any](breaker CircuitBreaker[A], ctx context.Context, action func(ctx context.Context) (A, error)) (A, error, bool) {
+	var ran atomic.Bool // Set from the goroutine running the action, which may outlive the call after a timeout.
+	value, err := ProtectContext(breaker, ctx, func(ctx context.Context) (A, error) {
+		ran.Store(true)
+		return action(ctx)
+	})
+	var timeoutErr *TimeoutError
+	return value, err, err != nil && !ran.Load() && !errors.As(err, &timeoutErr)
+}
+
+type chain[A any] struct {
+	policies []CircuitBreaker[A]
+}
+
+// Chain composes policies into one, the first being the outermost: it protects the call to the second,
+// which protects the call to the third, and so on down to the action. Each policy is given the context
+// passed on by the one before it.
+//
+// A typical order is fallback, retry, circuit breaker, rate limiter, timeout and bulkhead, so that
+// retries are rejected by an open circuit breaker, and timeouts are counted by it.
+//
+// The chain is an EventSource publishing the events of every policy that is one, identified by
+// Event.Policy and Event.Breaker.
+func Chain[A any](policies ...CircuitBreaker[A]) ContextCircuitBreaker[A] {
+	return &chain[A]{policies: policies}
+}
+
+func (c *chain[A]) Protect(ctx context.Context, action func() (A, error)) (A, error) {
+	return c.protect(ctx, 0, func(context.Context) (A, error) {
+		return action()
+	})
+}
+
+func (c *chain[A]) ProtectContext(ctx context.Context, action func(ctx context.Context) (A, error)) (A, error) {
+	return c.protect(ctx, 0, action)
+}
+
+func (c *chain[A]) protect(ctx context.Context, i int, action func(ctx context.Context) (A, error)) (A, error) {
+	if i == len(c.policies) {
+		return action(ctx)
+	}
+	return ProtectContext(c.policies[i], ctx, func(ctx context.Context) (A, error) {
+		return c.protect(ctx, i+1, action)
+	})
+}
+
+// Subscribe returns a subscription to the events of every policy, buffering up to buffer events from each.
+func (c *chain[A]) Subscribe(buffer int) *Subscription {
+	var sources []EventSource
+	for _, policy := range c.policies {
+		if source, ok := AsEventSource(policy); ok {
+			sources = append(sources, source)
+		}
+	}
+	return Merge(buffer, sources...)
+}
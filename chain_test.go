@@ -0,0 +1,94 @@
+package gocircuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type ctxKey string
+
+// recording is a policy recording the order it is called in and passing a context of its own to the action.
+type recording struct {
+	name   string
+	calls  *[]string
+	events *EventBus
+}
+
+func (r recording) Protect(ctx context.Context, action func() (int, error)) (int, error) {
+	panic("the chain should call ProtectContext")
+}
+
+func (r recording) ProtectContext(ctx context.Context, action func(ctx context.Context) (int, error)) (int, error) {
+	*r.calls = append(*r.calls, r.name)
+	r.events.Publish(Event{Breaker: r.name})
+	return action(context.WithValue(ctx, ctxKey(r.name), true))
+}
+
+func (r recording) Subscribe(buffer int) *Subscription {
+	return r.events.Subscribe(buffer)
+}
+
+func TestChainOrderAndContext(t *testing.T) {
+	var calls []string
+	outer := recording{name: "outer", calls: &calls, events: &EventBus{}}
+	inner := recording{name: "inner", calls: &calls, events: &EventBus{}}
+	c := Chain[int](outer, inner)
+	events := c.(EventSource).Subscribe(4)
+	defer events.Close()
+
+	value, err := c.ProtectContext(context.Background(), func(ctx context.Context) (int, error) {
+		if ctx.Value(ctxKey("outer")) == nil || ctx.Value(ctxKey("inner")) == nil {
+			t.Error("expected the context of every policy")
+		}
+		return 1, nil
+	})
+	if value != 1 || err != nil {
+		t.Fatalf("expected the action's result, got %d and %v", value, err)
+	}
+	if len(calls) != 2 || calls[0] != "outer" || calls[1] != "inner" {
+		t.Errorf("expected outer then inner, got %v", calls)
+	}
+
+	seen := map[string]bool{}
+	for len(seen) < 2 {
+		select {
+		case event := <-events.Events():
+			seen[event.Breaker] = true
+		case <-time.After(time.Second):
+			t.Fatalf("expected the events of both policies, got %v", seen)
+		}
+	}
+}
+
+// returning is a policy returning err, after running the action if run is set.
+type returning struct {
+	run bool
+	err error
+}
+
+func (r returning) Protect(ctx context.Context, action func() (int, error)) (int, error) {
+	if r.run {
+		_, _ = action()
+	}
+	return 0, r.err
+}
+
+func TestProtectObserved(t *testing.T) {
+	failed := errors.New("failed")
+	for name, test := range map[string]struct {
+		policy   returning
+		rejected bool
+	}{
+		"rejected":  {returning{err: ErrOpen}, true},
+		"failed":    {returning{run: true, err: failed}, false},
+		"succeeded": {returning{run: true}, false},
+		"timed out": {returning{err: &TimeoutError{Timeout: time.Second}}, false}, // Before the action started.
+	} {
+		_, err, rejected := ProtectObserved[int](test.policy, context.Background(), func(ctx context.Context) (int, error) { return 0, nil })
+		if err != test.policy.err || rejected != test.rejected {
+			t.Errorf("%s: got %v and rejected %t, want %v and %t", name, err, rejected, test.policy.err, test.rejected)
+		}
+	}
+}
@@ -5,6 +5,7 @@ package gocircuit
 
 import (
 	"context"
+	"time"
 )
 
 // CircuitBreaker is an interface that represents a circuit breaker.
@@ -17,3 +18,14 @@ type CircuitBreaker[A any] interface {
 	// Check(ctx context.Context) (bool, error)
 	// Report(ctx context.Context, success bool) error
 }
+
+// Controller is implemented by circuit breakers whose state can be
+// overridden by an operator.
+type Controller interface {
+	// Force pins the circuit breaker in a forced state. A zero expiry
+	// keeps it pinned until Reset is called.
+	Force(ctx context.Context, state State, expiry time.Duration) error
+	// Reset lifts any forced state and returns the circuit breaker to closed
+	// with empty counts.
+	Reset(ctx context.Context) error
+}
@@ -0,0 +1,233 @@
+// Command gocircuit inspects and manipulates circuit breakers stored in Redis by the realtime implementation.
+//
+// Usage:
+//
+//	gocircuit [flags] list
+//	gocircuit [flags] show KEY
+//	gocircuit [flags] reset KEY
+//	gocircuit [flags] open [-expiry DURATION] KEY
+//	gocircuit [flags] close [-expiry DURATION] KEY
+//	gocircuit [flags] watch [-every DURATION] KEY
+//
+// The -interval and -window-size flags must match the Interval and WindowSize of the breakers being inspected
+// for the counts to be those the breakers see. Inspecting never modifies a breaker.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+	gcredis "github.com/christopherdavenport/gocircuit/redis/realtime"
+	"github.com/redis/go-redis/v9"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:6379", "Redis address")
+	password := flag.String("password", "", "Redis password")
+	db := flag.Int("db", 0, "Redis database")
+	prefix := flag.String("prefix", "circuitBreaker", "the Prefix of the circuit breaker settings")
+	interval := flag.Duration("interval", time.Minute, "the Interval of the circuit breaker settings")
+	windowSize := flag.Int("window-size", 0, "the WindowSize of the circuit breaker settings, for breakers counting the last outcomes")
+	weighted := flag.Bool("weighted", false, "the Weighted of the circuit breaker settings, so show prints weighted counts, as it always does with -window-size")
+	auditMaxLen := flag.Int64("audit-max-len", 0, "the AuditMaxLen of the circuit breaker settings, so operator actions are audited")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(2)
+	}
+
+	hostname, _ := os.Hostname()
+	settings := gcredis.CircuitBreakerSettings{
+		Prefix:      *prefix,
+		Interval:    *interval,
+		WindowSize:  *windowSize,
+		AuditMaxLen: *auditMaxLen,
+		Weighted:    *weighted,
+		InstanceId:  fmt.Sprintf("gocircuit-cli:%s:%d", hostname, os.Getpid()),
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:     *addr,
+		Password: *password,
+		DB:       *db,
+	})
+	defer client.Close()
+
+	err := run(context.Background(), client, settings, flag.Arg(0), flag.Args()[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gocircuit:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage: gocircuit [flags] list|show|reset|open|close|watch [KEY]\n\nFlags:\n")
+	flag.PrintDefaults()
+}
+
+func run(ctx context.Context, client *redis.Client, settings gcredis.CircuitBreakerSettings, command string, args []string) error {
+	switch command {
+	case "list":
+		return list(ctx, client, settings)
+	case "show":
+		key, err := keyArg(command, args)
+		if err != nil {
+			return err
+		}
+		return show(ctx, client, settings, key)
+	case "reset":
+		key, err := keyArg(command, args)
+		if err != nil {
+			return err
+		}
+		return gcredis.Reset(client, ctx, key, settings)
+	case "open", "close":
+		flags := flag.NewFlagSet(command, flag.ExitOnError)
+		expiry := flags.Duration("expiry", 0, "how long the override lasts, zero until reset")
+		_ = flags.Parse(args)
+		key, err := keyArg(command, flags.Args())
+		if err != nil {
+			return err
+		}
+		state := gocircuit.StateForcedOpen
+		if command == "close" {
+			state = gocircuit.StateForcedClosed
+		}
+		return gcredis.ForceState(client, ctx, key, settings, state, *expiry)
+	case "watch":
+		flags := flag.NewFlagSet(command, flag.ExitOnError)
+		every := flags.Duration("every", time.Second, "how often to poll")
+		_ = flags.Parse(args)
+		key, err := keyArg(command, flags.Args())
+		if err != nil {
+			return err
+		}
+		return watch(ctx, client, settings, key, *every)
+	default:
+		return fmt.Errorf("unknown command: %s", command)
+	}
+}
+
+func keyArg(command string, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("%s takes exactly one KEY", command)
+	}
+	return args[0], nil
+}
+
+// keys finds every circuit breaker with a state or forced state under the prefix.
+func keys(ctx context.Context, client *redis.Client, settings gcredis.CircuitBreakerSettings) ([]string, error) {
+	found := map[string]bool{}
+	pattern := gcredis.KeysFor(settings.Prefix, "*")
+	for _, match := range []string{pattern.State, pattern.Force} {
+		iter := client.Scan(ctx, 0, match, 100).Iterator()
+		for iter.Next(ctx) {
+			if key, ok := gcredis.KeyFromStateKey(settings.Prefix, iter.Val()); ok {
+				found[key] = true
+			}
+		}
+		if err := iter.Err(); err != nil {
+			return nil, err
+		}
+	}
+	keys := make([]string, 0, len(found))
+	for key := range found {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func list(ctx context.Context, client *redis.Client, settings gcredis.CircuitBreakerSettings) error {
+	keys, err := keys(ctx, client, settings)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tSTATE\tREQUESTS\tSUCCESSES\tFAILURES\tCONSEC SUCC\tCONSEC FAIL\tOPEN UNTIL")
+	for _, key := range keys {
+		snapshot, err := gcredis.Inspect(client, ctx, key, settings)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		c := snapshot.Counts
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%s\n", key, snapshot.State, c.Requests, c.TotalSuccesses, c.TotalFailures, c.ConsecutiveSuccesses, c.ConsecutiveFailures, formatTime(snapshot.OpenUntil))
+	}
+	return w.Flush()
+}
+
+func show(ctx context.Context, client *redis.Client, settings gcredis.CircuitBreakerSettings, key string) error {
+	snapshot, err := gcredis.Inspect(client, ctx, key, settings)
+	if err != nil {
+		return err
+	}
+	c := snapshot.Counts
+	fmt.Printf("key:                   %s\n", key)
+	fmt.Printf("state:                 %s\n", snapshot.State)
+	fmt.Printf("open until:            %s\n", formatTime(snapshot.OpenUntil))
+	fmt.Printf("requests:              %d\n", c.Requests)
+	fmt.Printf("successes:             %d\n", c.TotalSuccesses)
+	fmt.Printf("failures:              %d\n", c.TotalFailures)
+	fmt.Printf("consecutive successes: %d\n", c.ConsecutiveSuccesses)
+	fmt.Printf("consecutive failures:  %d\n", c.ConsecutiveFailures)
+	if settings.Weighted || settings.WindowSize > 0 {
+		fmt.Printf("weighted requests:     %g\n", c.Weighted.Requests)
+		fmt.Printf("weighted successes:    %g\n", c.Weighted.TotalSuccesses)
+		fmt.Printf("weighted failures:     %g\n", c.Weighted.TotalFailures)
+	}
+
+	transitions, err := gcredis.ReadTransitions(client, ctx, key, settings, 20)
+	if err != nil {
+		return err
+	}
+	if len(transitions) > 0 {
+		fmt.Println()
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tFROM\tTO\tREASON\tINSTANCE")
+		for _, t := range transitions {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", formatTime(t.Time), t.From, t.To, t.Reason, t.Instance)
+		}
+		return w.Flush()
+	}
+	return nil
+}
+
+func watch(ctx context.Context, client *redis.Client, settings gcredis.CircuitBreakerSettings, key string, every time.Duration) error {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	var last string
+	for {
+		snapshot, err := gcredis.Inspect(client, ctx, key, settings)
+		if err != nil {
+			return err
+		}
+		c := snapshot.Counts
+		line := fmt.Sprintf("%s requests=%d successes=%d failures=%d consecutiveSuccesses=%d consecutiveFailures=%d", snapshot.State, c.Requests, c.TotalSuccesses, c.TotalFailures, c.ConsecutiveSuccesses, c.ConsecutiveFailures)
+		if !snapshot.OpenUntil.IsZero() {
+			line += " openUntil=" + formatTime(snapshot.OpenUntil)
+		}
+		if line != last {
+			fmt.Printf("%s %s\n", time.Now().Format(time.RFC3339), line)
+			last = line
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format(time.RFC3339)
+}
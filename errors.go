@@ -0,0 +1,65 @@
+package gocircuit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrOpen matches, with errors.Is, every rejection by an open or half-open circuit breaker.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// OpenError is a rejection by an open circuit breaker that knows when it may admit calls again.
+type OpenError struct {
+	Err   error     // The rejection returned by the implementation.
+	Until time.Time // When the circuit breaker may admit a call again. Zero if unknown.
+}
+
+func (e *OpenError) Error() string {
+	if e.Err == nil {
+		return ErrOpen.Error()
+	}
+	return e.Err.Error()
+}
+
+func (e *OpenError) Unwrap() error {
+	return e.Err
+}
+
+func (e *OpenError) Is(target error) bool {
+	return target == ErrOpen
+}
+
+// RetryAfter returns how long until the circuit breaker may admit a call again, or zero if that is unknown or past.
+func (e *OpenError) RetryAfter() time.Duration {
+	if e.Until.IsZero() {
+		return 0
+	}
+	return max(0, time.Until(e.Until))
+}
+
+// RetryAfter returns the wait suggested by the first error in err's tree with a RetryAfter method,
+// and whether there is one suggesting a positive wait.
+func RetryAfter(err error) (time.Duration, bool) {
+	var suggester interface{ RetryAfter() time.Duration }
+	if !errors.As(err, &suggester) {
+		return 0, false
+	}
+	wait := suggester.RetryAfter()
+	return wait, wait > 0
+}
+
+// TimeoutError is returned when an action is abandoned for running longer than Timeout.
+// It matches context.DeadlineExceeded with errors.Is, and always counts as a failure.
+type TimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("action timed out after %s", e.Timeout)
+}
+
+func (e *TimeoutError) Is(target error) bool {
+	return target == context.DeadlineExceeded
+}
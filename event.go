@@ -0,0 +1,212 @@
+package gocircuit
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventKind is the type of activity an Event describes.
+type EventKind int
+
+const (
+	EventAdmitted    EventKind = iota // The action is about to run.
+	EventRejected                     // The request was rejected without running the action.
+	EventSuccess                      // The action completed and was counted as a success.
+	EventFailure                      // The action completed and was counted as a failure.
+	EventIgnored                      // The action completed and was counted as neither, see OutcomeIgnore.
+	EventSlowCall                     // The action took longer than the slow call threshold, in addition to its outcome event.
+	EventStateChange                  // The circuit breaker changed state from From to State.
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventAdmitted:
+		return "admitted"
+	case EventRejected:
+		return "rejected"
+	case EventSuccess:
+		return "success"
+	case EventFailure:
+		return "failure"
+	case EventIgnored:
+		return "ignored"
+	case EventSlowCall:
+		return "slow call"
+	case EventStateChange:
+		return "state change"
+	default:
+		return fmt.Sprintf("unknown event kind: %d", int(k))
+	}
+}
+
+// EventKindOf returns the kind of event published for an action completing with outcome.
+func EventKindOf(outcome Outcome) EventKind {
+	switch outcome {
+	case OutcomeFailure:
+		return EventFailure
+	case OutcomeIgnore:
+		return EventIgnored
+	default:
+		return EventSuccess
+	}
+}
+
+// Policies publishing events, see Event.Policy.
+const (
+	PolicyCircuitBreaker = "circuit breaker"
+	PolicyBulkhead       = "bulkhead"
+	PolicyAdaptive       = "adaptive throttle"
+	PolicyRateLimit      = "rate limit"
+)
+
+// Event describes a single piece of circuit breaker activity.
+type Event struct {
+	Kind     EventKind
+	Policy   string // The kind of policy publishing the event, such as PolicyCircuitBreaker.
+	Breaker  string // The name or key of the circuit breaker.
+	Time     time.Time
+	State    State         // The state the request was admitted or rejected in, or the new state of a state change.
+	From     State         // The previous state of a state change.
+	Err      error         // The error returned, if any.
+	Duration time.Duration // How long the action ran, for outcome events.
+}
+
+// EventSource is implemented by circuit breakers publishing their activity as events.
+type EventSource interface {
+	Subscribe(buffer int) *Subscription
+}
+
+// Subscription receives the events published after it was created.
+type Subscription struct {
+	events  chan Event
+	dropped atomic.Uint64
+	bus     *EventBus
+	once    sync.Once
+	closed  atomic.Bool
+
+	merged []*Subscription // The subscriptions forwarded to this one by Merge.
+}
+
+// Events returns the channel events are delivered on. It is closed by Close.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Dropped returns the number of events discarded because the buffer was full.
+func (s *Subscription) Dropped() uint64 {
+	dropped := s.dropped.Load()
+	for _, m := range s.merged {
+		dropped += m.Dropped()
+	}
+	return dropped
+}
+
+// Closed reports whether no more events will be delivered, because Close has been called or every source
+// merged into s has been closed. Subscribing to a source publishing no events, such as a nil EventBus,
+// returns a closed subscription.
+func (s *Subscription) Closed() bool {
+	if s.closed.Load() {
+		return true
+	}
+	if len(s.merged) == 0 {
+		return false
+	}
+	for _, m := range s.merged {
+		if !m.Closed() {
+			return false
+		}
+	}
+	return true
+}
+
+// Close stops delivery and closes the events channel.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.closed.Store(true)
+		if s.bus != nil {
+			s.bus.mu.Lock()
+			delete(s.bus.subscriptions, s)
+			s.bus.mu.Unlock()
+		}
+		close(s.events)
+		for _, m := range s.merged {
+			m.Close()
+		}
+	})
+}
+
+// Merge returns a single subscription to the events of every source, buffering up to buffer events from each.
+// Events of different sources may be delivered out of order, see Event.Time.
+func Merge(buffer int, sources ...EventSource) *Subscription {
+	bus := &EventBus{}
+	s := bus.Subscribe(buffer)
+	for _, source := range sources {
+		m := source.Subscribe(buffer)
+		s.merged = append(s.merged, m)
+		go func() {
+			for event := range m.events {
+				bus.Publish(event)
+			}
+		}()
+	}
+	return s
+}
+
+// EventBus delivers published events to every subscription without ever
+// blocking the publisher: when a subscription's buffer is full the event is
+// dropped and counted instead.
+//
+// The zero value is ready to use, and publishing to a nil EventBus does nothing.
+type EventBus struct {
+	mu            sync.RWMutex
+	subscriptions map[*Subscription]struct{}
+}
+
+// Subscribe returns a subscription buffering up to buffer undelivered events.
+func (b *EventBus) Subscribe(buffer int) *Subscription {
+	s := &Subscription{events: make(chan Event, buffer), bus: b}
+	if b == nil {
+		s.Close()
+		return s
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subscriptions == nil {
+		b.subscriptions = map[*Subscription]struct{}{}
+	}
+	b.subscriptions[s] = struct{}{}
+	return s
+}
+
+// SubscribeFunc calls f with every event from a new subscription buffering up to buffer events,
+// until the subscription is closed.
+func (b *EventBus) SubscribeFunc(buffer int, f func(Event)) *Subscription {
+	s := b.Subscribe(buffer)
+	go func() {
+		for event := range s.events {
+			f(event)
+		}
+	}()
+	return s
+}
+
+// Publish delivers event to every subscription, setting its Time if unset.
+func (b *EventBus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for s := range b.subscriptions {
+		select {
+		case s.events <- event:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package gocircuit
+
+import (
+	"testing"
+	"time"
+)
+
+func receive(t *testing.T, s *Subscription) Event {
+	t.Helper()
+	select {
+	case event := <-s.Events():
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event delivered")
+		return Event{}
+	}
+}
+
+func TestEventBusDropsWhenFull(t *testing.T) {
+	bus := &EventBus{}
+	s := bus.Subscribe(1)
+	bus.Publish(Event{Kind: EventAdmitted})
+	bus.Publish(Event{Kind: EventSuccess})
+	if event := receive(t, s); event.Kind != EventAdmitted || event.Time.IsZero() {
+		t.Errorf("expected the first event with its time set, got %+v", event)
+	}
+	if s.Dropped() != 1 {
+		t.Errorf("expected 1 dropped event, got %d", s.Dropped())
+	}
+
+	s.Close()
+	s.Close()
+	bus.Publish(Event{Kind: EventFailure}) // Must not panic on the closed subscription.
+	if _, ok := <-s.Events(); ok {
+		t.Error("expected the events channel to be closed")
+	}
+}
+
+func TestNilEventBus(t *testing.T) {
+	var bus *EventBus
+	bus.Publish(Event{})
+	if _, ok := <-bus.Subscribe(1).Events(); ok {
+		t.Error("expected a subscription to a nil bus to be closed")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a, b := &EventBus{}, &EventBus{}
+	s := Merge(4, a, b)
+	a.Publish(Event{Breaker: "a"})
+	if event := receive(t, s); event.Breaker != "a" {
+		t.Errorf("expected the event of a, got %+v", event)
+	}
+	b.Publish(Event{Breaker: "b"})
+	if event := receive(t, s); event.Breaker != "b" {
+		t.Errorf("expected the event of b, got %+v", event)
+	}
+
+	s.Close()
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if len(a.subscriptions) != 0 {
+		t.Error("expected closing the merged subscription to close those it merged")
+	}
+}
+
+func TestClosedSubscription(t *testing.T) {
+	var nilBus *EventBus
+	if !nilBus.Subscribe(1).Closed() {
+		t.Error("expected a subscription to a nil bus to be closed")
+	}
+	bus := &EventBus{}
+	s := bus.Subscribe(1)
+	if s.Closed() {
+		t.Error("expected a new subscription to be open")
+	}
+	if !Merge(1, nilBus, nilBus).Closed() {
+		t.Error("expected a merge of closed subscriptions to be closed")
+	}
+	if Merge(1, nilBus, bus).Closed() {
+		t.Error("expected a merge with an open subscription to be open")
+	}
+	s.Close()
+	if !s.Closed() {
+		t.Error("expected a subscription to be closed by Close")
+	}
+}
+
+func TestEventKindOf(t *testing.T) {
+	for outcome, kind := range map[Outcome]EventKind{
+		OutcomeSuccess: EventSuccess,
+		OutcomeFailure: EventFailure,
+		OutcomeIgnore:  EventIgnored,
+	} {
+		if got := EventKindOf(outcome); got != kind {
+			t.Errorf("EventKindOf(%s) = %s, want %s", outcome, got, kind)
+		}
+	}
+}
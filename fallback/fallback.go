@@ -0,0 +1,56 @@
+// Package fallback replaces the errors of protected actions, including rejections, with a fallback result.
+package fallback
+
+import (
+	"context"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+// Settings configures the fallback made by New.
+type Settings[A any] struct {
+	// Fallback returns the result used instead of err. Its error, if any, is returned from Protect.
+	Fallback func(ctx context.Context, err error) (A, error)
+	// When decides which errors fall back. Defaults to every error.
+	When func(err error) bool
+}
+
+type fallback[A any] struct {
+	circuit  gocircuit.CircuitBreaker[A]
+	settings Settings[A]
+}
+
+// New returns a circuit breaker falling back according to settings when the action protected by circuit,
+// or circuit itself, returns an error.
+//
+// A nil circuit falls back from the errors of the action alone, for use as a policy in gocircuit.Chain.
+func New[A any](circuit gocircuit.CircuitBreaker[A], settings Settings[A]) gocircuit.ContextCircuitBreaker[A] {
+	if settings.When == nil {
+		settings.When = func(err error) bool { return true }
+	}
+	return &fallback[A]{circuit: circuit, settings: settings}
+}
+
+func (f *fallback[A]) Protect(ctx context.Context, action func() (A, error)) (A, error) {
+	return f.ProtectContext(ctx, func(context.Context) (A, error) {
+		return action()
+	})
+}
+
+func (f *fallback[A]) ProtectContext(ctx context.Context, action func(ctx context.Context) (A, error)) (A, error) {
+	var value A
+	var err error
+	if f.circuit == nil {
+		value, err = action(ctx)
+	} else {
+		value, err = gocircuit.ProtectContext(f.circuit, ctx, action)
+	}
+	if err == nil || !f.settings.When(err) {
+		return value, err
+	}
+	return f.settings.Fallback(ctx, err)
+}
+
+func (f *fallback[A]) Unwrap() any {
+	return f.circuit
+}
@@ -2,18 +2,239 @@ package gobreaker
 
 import (
 	"context"
+	"fmt"
+	"time"
+
 	"github.com/christopherdavenport/gocircuit"
+	"github.com/christopherdavenport/gocircuit/timeout"
 	gb "github.com/sony/gobreaker/v2"
 )
 
 type goBreakerCircuit[A any] struct {
-	circuit *gb.CircuitBreaker[A]
+	circuit           *gb.CircuitBreaker[A]
+	events            *gocircuit.EventBus
+	isSuccessful      func(err error) bool
+	isExcluded        func(err error) bool
+	slowCallThreshold time.Duration
+	panicMode         gocircuit.PanicMode
+	timeout           time.Duration
+
+	classify gocircuit.Classifier[A]
+	// classifies is set when the gobreaker settings count outcomes from the classifiedError returned by the action.
+	classifies bool
+}
+
+// classifiedError carries the outcome decided by the adapter through gobreaker to its IsSuccessful and IsExcluded.
+type classifiedError struct {
+	outcome gocircuit.Outcome
+	err     error
+}
+
+func (e *classifiedError) Error() string {
+	if e.err == nil {
+		return e.outcome.String()
+	}
+	return e.err.Error()
+}
+
+func (e *classifiedError) Unwrap() error {
+	return e.err
 }
 
 func (g *goBreakerCircuit[A]) Protect(ctx context.Context, action func() (A, error)) (A, error) {
-	return g.circuit.Execute(action)
+	return g.ProtectContext(ctx, func(context.Context) (A, error) {
+		return action()
+	})
+}
+
+func (g *goBreakerCircuit[A]) ProtectContext(ctx context.Context, action func(ctx context.Context) (A, error)) (A, error) {
+	run := func() (A, error) {
+		return action(ctx)
+	}
+	if g.timeout > 0 {
+		run = func() (A, error) {
+			return timeout.Run(ctx, g.timeout, action)
+		}
+	}
+	state, _ := StateFromGoBreaker(g.circuit.State())
+	var ran bool
+	var duration time.Duration
+	var outcome gocircuit.Outcome
+	value, err := g.circuit.Execute(func() (A, error) {
+		ran = true
+		g.publish(gocircuit.Event{Kind: gocircuit.EventAdmitted, State: state})
+		start := time.Now()
+		value, err := gocircuit.Recover(run)()
+		duration = time.Since(start)
+		outcome = g.outcome(ctx, value, err)
+		if g.classifies {
+			return value, &classifiedError{outcome: outcome, err: err}
+		}
+		return value, err
+	})
+	if !ran {
+		if err == gb.ErrOpenState || err == gb.ErrTooManyRequests {
+			err = &gocircuit.OpenError{Err: err} // gobreaker does not tell when it will admit calls again.
+		}
+		g.publish(gocircuit.Event{Kind: gocircuit.EventRejected, State: state, Err: err})
+		return value, err
+	}
+	if classified, ok := err.(*classifiedError); ok {
+		err = classified.err
+	}
+	defer g.panicMode.Rethrow(err) // Only once the panic has been counted and published as a failure.
+
+	gocircuit.ObserveOutcome(ctx, outcome)
+	kind := gocircuit.EventKindOf(outcome)
+	g.publish(gocircuit.Event{Kind: kind, State: state, Err: err, Duration: duration})
+	if g.slowCallThreshold > 0 && duration > g.slowCallThreshold {
+		g.publish(gocircuit.Event{Kind: gocircuit.EventSlowCall, State: state, Err: err, Duration: duration})
+	}
+	return value, err
+}
+
+func (g *goBreakerCircuit[A]) outcome(ctx context.Context, value A, err error) gocircuit.Outcome {
+	if g.classify == nil && err != nil && g.isExcluded != nil && g.isExcluded(err) {
+		return gocircuit.OutcomeIgnore
+	}
+	return gocircuit.Classify(ctx, g.classify, g.isSuccessful, value, err)
+}
+
+func (g *goBreakerCircuit[A]) publish(event gocircuit.Event) {
+	event.Policy, event.Breaker = gocircuit.PolicyCircuitBreaker, g.circuit.Name()
+	g.events.Publish(event)
+}
+
+// Subscribe returns a subscription to the activity of the circuit breaker, buffering up to buffer events.
+func (g *goBreakerCircuit[A]) Subscribe(buffer int) *gocircuit.Subscription {
+	return g.events.Subscribe(buffer)
+}
+
+func (g *goBreakerCircuit[A]) Inspect(ctx context.Context) (gocircuit.Snapshot, error) {
+	state, err := StateFromGoBreaker(g.circuit.State())
+	if err != nil {
+		return gocircuit.Snapshot{}, err
+	}
+	counts := g.circuit.Counts()
+	return gocircuit.Snapshot{
+		State: state,
+		Counts: gocircuit.Counts{
+			Requests:             int64(counts.Requests),
+			TotalSuccesses:       int64(counts.TotalSuccesses),
+			TotalFailures:        int64(counts.TotalFailures),
+			ConsecutiveSuccesses: int64(counts.ConsecutiveSuccesses),
+			ConsecutiveFailures:  int64(counts.ConsecutiveFailures),
+		}.WithUnitWeights(),
+	}, nil
 }
 
+// NewGoBreakerCircuitBreaker adapts an existing gobreaker circuit breaker. Like NewCircuitBreaker, it rejects
+// requests with a *gocircuit.OpenError wrapping gb.ErrOpenState or gb.ErrTooManyRequests, which matches both
+// the gobreaker error and gocircuit.ErrOpen with errors.Is.
+//
+// The gobreaker settings of breaker are not visible to the adapter, so the outcomes it publishes as events, and
+// observes with gocircuit.ObserveOutcome, are classified by the defaults of gocircuit.Classify: any error is a
+// failure, except the caller canceling the context, which is ignored. gobreaker itself counts with its own
+// IsSuccessful and IsExcluded, which count a canceled context as a failure unless they exclude it, so metrics
+// recorded from the events may disagree with its counts. Use NewCircuitBreaker for outcomes that always agree.
 func NewGoBreakerCircuitBreaker[A any](breaker *gb.CircuitBreaker[A]) gocircuit.CircuitBreaker[A] {
-	return &goBreakerCircuit[A]{circuit: breaker}
+	return &goBreakerCircuit[A]{circuit: breaker, events: &gocircuit.EventBus{}}
+}
+
+// Settings configures a gobreaker circuit breaker created by NewCircuitBreaker.
+//
+// StateListeners are called asynchronously, in order, after every state change, in addition to the
+// synchronous gobreaker OnStateChange. Errors they return are passed to OnListenerError as a *gocircuit.ListenerError.
+//
+// Actions running longer than SlowCallThreshold publish a slow call event. Zero disables it.
+//
+// A panicking action always counts as a failure. PanicMode decides whether Protect then panics again or returns a *gocircuit.PanicError.
+//
+// Actions running longer than Timeout are abandoned with a *gocircuit.TimeoutError, counted as a failure. Zero disables it.
+type Settings struct {
+	gb.Settings
+	StateListeners    []gocircuit.StateListener
+	OnListenerError   func(err error)
+	SlowCallThreshold time.Duration
+	PanicMode         gocircuit.PanicMode
+	Timeout           time.Duration
+}
+
+type listenedGoBreakerCircuit[A any] struct {
+	*goBreakerCircuit[A]
+	notifier *gocircuit.Notifier
+}
+
+// AddStateListener registers listener for every state change from now on.
+func (l *listenedGoBreakerCircuit[A]) AddStateListener(listener gocircuit.StateListener) {
+	l.notifier.Add(listener)
+}
+
+// NewCircuitBreaker creates a gobreaker circuit breaker from settings, bridging its OnStateChange to gocircuit state listeners.
+// A caller canceling the context is counted as neither a success nor a failure. Requests are rejected with a
+// *gocircuit.OpenError wrapping gb.ErrOpenState or gb.ErrTooManyRequests, so compare them with errors.Is.
+func NewCircuitBreaker[A any](settings Settings) gocircuit.CircuitBreaker[A] {
+	return NewCircuitBreakerWithClassifier[A](settings, nil)
+}
+
+// NewCircuitBreakerWithClassifier is NewCircuitBreaker deciding outcomes with classify rather than IsSuccessful and IsExcluded,
+// so results as well as errors can count as failures.
+func NewCircuitBreakerWithClassifier[A any](settings Settings, classify gocircuit.Classifier[A]) gocircuit.CircuitBreaker[A] {
+	isSuccessful := settings.IsSuccessful
+	isExcluded := settings.IsExcluded
+	settings.IsSuccessful = func(err error) bool {
+		classified, ok := err.(*classifiedError)
+		return ok && classified.outcome == gocircuit.OutcomeSuccess
+	}
+	settings.IsExcluded = func(err error) bool {
+		classified, ok := err.(*classifiedError)
+		return ok && classified.outcome == gocircuit.OutcomeIgnore
+	}
+
+	notifier := gocircuit.NewNotifier(settings.OnListenerError, settings.StateListeners...)
+	events := &gocircuit.EventBus{}
+	onStateChange := settings.OnStateChange
+	settings.OnStateChange = func(name string, from gb.State, to gb.State) {
+		if onStateChange != nil {
+			onStateChange(name, from, to)
+		}
+		old, err := StateFromGoBreaker(from)
+		if err != nil {
+			return
+		}
+		new, err := StateFromGoBreaker(to)
+		if err != nil {
+			return
+		}
+		notifier.Notify(old, new)
+		events.Publish(gocircuit.Event{Kind: gocircuit.EventStateChange, Policy: gocircuit.PolicyCircuitBreaker, Breaker: name, From: old, State: new})
+	}
+	return &listenedGoBreakerCircuit[A]{
+		goBreakerCircuit: &goBreakerCircuit[A]{
+			circuit:           gb.NewCircuitBreaker[A](settings.Settings),
+			events:            events,
+			isSuccessful:      isSuccessful,
+			isExcluded:        isExcluded,
+			slowCallThreshold: settings.SlowCallThreshold,
+			panicMode:         settings.PanicMode,
+			timeout:           settings.Timeout,
+			classify:          classify,
+			classifies:        true,
+		},
+		notifier: notifier,
+	}
+}
+
+// StateFromGoBreaker converts a gobreaker state to the equivalent gocircuit state.
+func StateFromGoBreaker(s gb.State) (gocircuit.State, error) {
+	switch s {
+	case gb.StateClosed:
+		return gocircuit.StateClosed, nil
+	case gb.StateHalfOpen:
+		return gocircuit.StateHalfOpen, nil
+	case gb.StateOpen:
+		return gocircuit.StateOpen, nil
+	default:
+		return gocircuit.StateClosed, fmt.Errorf("unknown state: %d", s)
+	}
 }
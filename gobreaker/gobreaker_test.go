@@ -0,0 +1,104 @@
+package gobreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+	"github.com/christopherdavenport/gocircuit/retry"
+	gb "github.com/sony/gobreaker/v2"
+)
+
+// counting counts the calls made through it.
+type counting struct {
+	calls int
+}
+
+func (c *counting) Protect(ctx context.Context, action func() (int, error)) (int, error) {
+	c.calls++
+	return action()
+}
+
+func newTrippingBreaker() gocircuit.CircuitBreaker[int] {
+	return NewCircuitBreaker[int](Settings{Settings: gb.Settings{
+		Name:        "test",
+		Timeout:     time.Minute,
+		ReadyToTrip: func(counts gb.Counts) bool { return counts.ConsecutiveFailures >= 1 },
+	}})
+}
+
+func TestRejectionMatchesBothSentinels(t *testing.T) {
+	breaker := newTrippingBreaker()
+	_, _ = breaker.Protect(context.Background(), func() (int, error) { return 0, errors.New("failed") })
+
+	_, err := breaker.Protect(context.Background(), func() (int, error) { return 0, nil })
+	if !errors.Is(err, gb.ErrOpenState) || !errors.Is(err, gocircuit.ErrOpen) {
+		t.Errorf("expected a rejection matching gb.ErrOpenState and gocircuit.ErrOpen, got %v", err)
+	}
+}
+
+func TestChainedRetryStopsAtOpenBreaker(t *testing.T) {
+	breaker := newTrippingBreaker()
+	_, _ = breaker.Protect(context.Background(), func() (int, error) { return 0, errors.New("failed") })
+
+	calls := &counting{}
+	chain := gocircuit.Chain[int](retry.New[int](nil, retry.Settings[int]{MaxAttempts: 5, InitialBackoff: time.Millisecond}), calls, breaker)
+	_, err := chain.Protect(context.Background(), func() (int, error) { return 0, nil })
+	if !errors.Is(err, gocircuit.ErrOpen) {
+		t.Fatalf("expected a rejection, got %v", err)
+	}
+	if calls.calls != 1 {
+		t.Errorf("expected the open breaker to be called once, got %d", calls.calls)
+	}
+}
+
+func TestClassifierCountsResults(t *testing.T) {
+	// Counts a 503 as a failure and ignores a 404, whatever the error.
+	classify := func(status int, err error) gocircuit.Outcome {
+		switch {
+		case status == 503 || err != nil:
+			return gocircuit.OutcomeFailure
+		case status == 404:
+			return gocircuit.OutcomeIgnore
+		default:
+			return gocircuit.OutcomeSuccess
+		}
+	}
+	breaker := NewCircuitBreakerWithClassifier[int](Settings{Settings: gb.Settings{Name: "test"}}, classify)
+	for _, status := range []int{200, 503, 404, 404} {
+		if _, err := breaker.Protect(context.Background(), func() (int, error) { return status, nil }); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snapshot, err := breaker.(gocircuit.Inspector).Inspect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// gobreaker counts every admitted request, but ignored ones as neither a success nor a failure.
+	if c := snapshot.Counts; c.TotalSuccesses != 1 || c.TotalFailures != 1 || c.ConsecutiveFailures != 1 {
+		t.Errorf("expected a success and a failure counted, the rest ignored, got %+v", c)
+	}
+}
+
+func TestCallerCancelIgnored(t *testing.T) {
+	breaker := newTrippingBreaker()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < 3; i++ {
+		_, err := breaker.Protect(ctx, func() (int, error) { return 0, ctx.Err() })
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected the cancellation, not a rejection, got %v", err)
+		}
+	}
+
+	snapshot, err := breaker.(gocircuit.Inspector).Inspect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.State != gocircuit.StateClosed || snapshot.Counts.TotalFailures != 0 {
+		t.Errorf("expected canceled requests not to count as failures, got %s with %+v", snapshot.State, snapshot.Counts)
+	}
+}
@@ -0,0 +1,98 @@
+// Package hedge sends a second attempt of slow actions, returning whichever succeeds first.
+package hedge
+
+import (
+	"context"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+type hedge[A any] struct {
+	circuit   gocircuit.CircuitBreaker[A]
+	inspector gocircuit.Inspector
+	delay     time.Duration
+}
+
+// New returns a circuit breaker that, if an action protected by circuit has not finished after delay, runs it
+// a second time concurrently and returns the first success, canceling the context of the other attempt.
+// If both fail, the error of the last to finish is returned.
+//
+// Both attempts run inside a single call to circuit, so it counts one outcome however many attempts there were.
+// If circuit implements gocircuit.Inspector, no second attempt is made while it is half-open, nor if its state
+// cannot be inspected.
+//
+// A nil circuit hedges the action alone, for use as a policy in gocircuit.Chain.
+func New[A any](circuit gocircuit.CircuitBreaker[A], delay time.Duration) gocircuit.ContextCircuitBreaker[A] {
+	inspector, _ := gocircuit.AsInspector(circuit)
+	return &hedge[A]{circuit: circuit, inspector: inspector, delay: delay}
+}
+
+func (h *hedge[A]) Protect(ctx context.Context, action func() (A, error)) (A, error) {
+	return h.ProtectContext(ctx, func(context.Context) (A, error) {
+		return action()
+	})
+}
+
+func (h *hedge[A]) ProtectContext(ctx context.Context, action func(ctx context.Context) (A, error)) (A, error) {
+	hedged := func(ctx context.Context) (A, error) {
+		return h.run(ctx, action)
+	}
+	if h.circuit == nil {
+		return hedged(ctx)
+	}
+	return gocircuit.ProtectContext(h.circuit, ctx, hedged)
+}
+
+type result[A any] struct {
+	value A
+	err   error
+}
+
+func (h *hedge[A]) run(ctx context.Context, action func(ctx context.Context) (A, error)) (A, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel() // Cancels the attempt still running, if any.
+
+	results := make(chan result[A], 2)
+	attempt := func() {
+		go func() {
+			value, err := gocircuit.Recover(func() (A, error) { return action(ctx) })()
+			results <- result[A]{value: value, err: err}
+		}()
+	}
+	attempt()
+	running := 1
+
+	timer := time.NewTimer(h.delay)
+	defer timer.Stop()
+	hedgeAfter := timer.C
+	for {
+		select {
+		case <-hedgeAfter:
+			hedgeAfter = nil
+			if !h.suppressed(ctx) {
+				attempt()
+				running++
+			}
+		case r := <-results:
+			running--
+			if r.err == nil || running == 0 {
+				gocircuit.PanicRepanic.Rethrow(r.err) // Raise a panic in the caller, where circuit can recover it.
+				return r.value, r.err
+			}
+		}
+	}
+}
+
+// suppressed reports whether the circuit breaker is half-open, or its state is unknown.
+func (h *hedge[A]) suppressed(ctx context.Context) bool {
+	if h.inspector == nil {
+		return false
+	}
+	snapshot, err := h.inspector.Inspect(ctx)
+	return err != nil || snapshot.State == gocircuit.StateHalfOpen
+}
+
+func (h *hedge[A]) Unwrap() any {
+	return h.circuit
+}
@@ -0,0 +1,81 @@
+package hedge
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+func TestSecondAttemptWins(t *testing.T) {
+	var attempts atomic.Int32
+	canceled := make(chan struct{})
+	h := New[int](nil, 5*time.Millisecond)
+	value, err := h.ProtectContext(context.Background(), func(ctx context.Context) (int, error) {
+		if attempts.Add(1) == 1 {
+			<-ctx.Done() // Slow until the second attempt wins.
+			close(canceled)
+			return 0, ctx.Err()
+		}
+		return 2, nil
+	})
+	if value != 2 || err != nil {
+		t.Fatalf("expected the second attempt's result, got %d and %v", value, err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("expected the losing attempt to be canceled")
+	}
+}
+
+func TestFastActionNotHedged(t *testing.T) {
+	var attempts atomic.Int32
+	h := New[int](nil, 50*time.Millisecond)
+	_, _ = h.Protect(context.Background(), func() (int, error) {
+		attempts.Add(1)
+		return 1, nil
+	})
+	if n := attempts.Load(); n != 1 {
+		t.Errorf("expected a single attempt, got %d", n)
+	}
+}
+
+func TestBothFail(t *testing.T) {
+	failed := errors.New("failed")
+	h := New[int](nil, time.Millisecond)
+	_, err := h.Protect(context.Background(), func() (int, error) {
+		time.Sleep(5 * time.Millisecond)
+		return 0, failed
+	})
+	if err != failed {
+		t.Errorf("expected the failure, got %v", err)
+	}
+}
+
+// halfOpen is a pass-through circuit breaker reporting itself half-open.
+type halfOpen struct{}
+
+func (halfOpen) Protect(ctx context.Context, action func() (int, error)) (int, error) {
+	return action()
+}
+
+func (halfOpen) Inspect(ctx context.Context) (gocircuit.Snapshot, error) {
+	return gocircuit.Snapshot{State: gocircuit.StateHalfOpen}, nil
+}
+
+func TestNoHedgeWhileHalfOpen(t *testing.T) {
+	var attempts atomic.Int32
+	h := New[int](halfOpen{}, time.Millisecond)
+	_, _ = h.Protect(context.Background(), func() (int, error) {
+		attempts.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return 1, nil
+	})
+	if n := attempts.Load(); n != 1 {
+		t.Errorf("expected no second attempt while half-open, got %d attempts", n)
+	}
+}
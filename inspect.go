@@ -0,0 +1,89 @@
+package gocircuit
+
+import (
+	"context"
+	"time"
+)
+
+// Counts holds the numbers of requests and their outcomes within the window of a circuit breaker.
+type Counts struct {
+	Requests             int64
+	TotalSuccesses       int64
+	TotalFailures        int64
+	ConsecutiveSuccesses int64
+	ConsecutiveFailures  int64
+
+	Weighted WeightedCounts // The same counts summing the weights of the outcomes, see WithWeight.
+}
+
+// Reasons recorded alongside a Transition by the implementations in this module.
+const (
+	ReasonReadyToTrip       = "ready to trip"
+	ReasonOpenTimeout       = "open timeout elapsed"
+	ReasonProbeFailed       = "half-open probe failed"
+	ReasonProbeSucceeded    = "half-open probe succeeded"
+	ReasonProbeIgnored      = "half-open probe ignored"
+	ReasonHealthCheckPassed = "health check passed"
+	ReasonForced            = "forced by operator"
+	ReasonReset             = "reset by operator"
+)
+
+// Transition is a single recorded state change of a circuit breaker.
+type Transition struct {
+	Id       string
+	From     State
+	To       State
+	Counts   Counts // The counts observed when the transition was made.
+	Reason   string
+	Instance string // Identifies the process that made the transition.
+	Time     time.Time
+}
+
+// Snapshot is a point in time view of a circuit breaker.
+type Snapshot struct {
+	State       State
+	Counts      Counts
+	OpenUntil   time.Time    // When an open circuit breaker will next admit a probe, or when a forced state expires. Zero if not applicable.
+	Transitions []Transition // Recent transitions, newest first, if the implementation records them.
+}
+
+// Inspector is implemented by circuit breakers that can report their current state.
+type Inspector interface {
+	Inspect(ctx context.Context) (Snapshot, error)
+}
+
+// Wrapper is implemented by decorators so that the interfaces of the
+// circuit breaker they wrap can still be found, see AsInspector.
+type Wrapper interface {
+	Unwrap() any
+}
+
+// AsInspector finds the first Inspector in the chain of decorators starting at breaker.
+func AsInspector(breaker any) (Inspector, bool) {
+	return find[Inspector](breaker)
+}
+
+// AsController finds the first Controller in the chain of decorators starting at breaker.
+func AsController(breaker any) (Controller, bool) {
+	return find[Controller](breaker)
+}
+
+// AsEventSource finds the first EventSource in the chain of decorators starting at breaker.
+func AsEventSource(breaker any) (EventSource, bool) {
+	return find[EventSource](breaker)
+}
+
+func find[T any](breaker any) (T, bool) {
+	for breaker != nil {
+		if t, ok := breaker.(T); ok {
+			return t, true
+		}
+		wrapper, ok := breaker.(Wrapper)
+		if !ok {
+			break
+		}
+		breaker = wrapper.Unwrap()
+	}
+	var t T
+	return t, false
+}
@@ -0,0 +1,103 @@
+package gocircuit
+
+import (
+	"fmt"
+	"sync"
+)
+
+// StateListener is called after a circuit breaker has changed state.
+type StateListener func(old State, new State) error
+
+// Listenable is implemented by circuit breakers that accept state listeners after construction.
+type Listenable interface {
+	AddStateListener(listener StateListener)
+}
+
+// ListenerError is reported when a StateListener returns an error.
+type ListenerError struct {
+	From State
+	To   State
+	Err  error
+}
+
+func (e *ListenerError) Error() string {
+	return fmt.Sprintf("state listener failed for %s -> %s: %s", e.From, e.To, e.Err)
+}
+
+func (e *ListenerError) Unwrap() error {
+	return e.Err
+}
+
+type stateChange struct {
+	from State
+	to   State
+}
+
+// Notifier delivers state changes to its listeners asynchronously, so a slow
+// listener never holds up the circuit breaker. Changes are delivered one at a
+// time in the order they were notified. Errors returned from listeners are
+// passed to OnError as a *ListenerError.
+//
+// The zero value is ready to use.
+type Notifier struct {
+	OnError func(err error)
+
+	mu        sync.Mutex
+	listeners []StateListener
+	pending   []stateChange
+	running   bool
+}
+
+// NewNotifier returns a Notifier delivering to listeners, skipping any that are nil.
+func NewNotifier(onError func(err error), listeners ...StateListener) *Notifier {
+	n := &Notifier{OnError: onError}
+	for _, listener := range listeners {
+		n.Add(listener)
+	}
+	return n
+}
+
+// Add registers listener for every change notified from now on.
+func (n *Notifier) Add(listener StateListener) {
+	if listener == nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.listeners = append(n.listeners, listener)
+}
+
+// Notify queues a change from old to new for delivery and returns immediately.
+func (n *Notifier) Notify(old State, new State) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.listeners) == 0 {
+		return
+	}
+	n.pending = append(n.pending, stateChange{from: old, to: new})
+	if !n.running {
+		n.running = true
+		go n.deliver()
+	}
+}
+
+func (n *Notifier) deliver() {
+	for {
+		n.mu.Lock()
+		if len(n.pending) == 0 {
+			n.running = false
+			n.mu.Unlock()
+			return
+		}
+		change := n.pending[0]
+		n.pending = n.pending[1:]
+		listeners := n.listeners
+		n.mu.Unlock()
+
+		for _, listener := range listeners {
+			if err := listener(change.from, change.to); err != nil && n.OnError != nil {
+				n.OnError(&ListenerError{From: change.from, To: change.to, Err: err})
+			}
+		}
+	}
+}
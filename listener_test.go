@@ -0,0 +1,44 @@
+package gocircuit
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNotifierDeliversInOrder(t *testing.T) {
+	type change struct{ from, to State }
+	changes := make(chan change, 3)
+	errs := make(chan error, 3)
+	failing := errors.New("failing")
+	n := NewNotifier(func(err error) { errs <- err }, nil, func(from State, to State) error {
+		changes <- change{from, to}
+		return failing
+	})
+
+	n.Notify(StateClosed, StateOpen)
+	n.Notify(StateOpen, StateHalfOpen)
+	n.Notify(StateHalfOpen, StateClosed)
+	for _, want := range []change{{StateClosed, StateOpen}, {StateOpen, StateHalfOpen}, {StateHalfOpen, StateClosed}} {
+		select {
+		case got := <-changes:
+			if got != want {
+				t.Errorf("expected %v, got %v", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%v not delivered", want)
+		}
+		var listenerErr *ListenerError
+		if err := <-errs; !errors.As(err, &listenerErr) || listenerErr.From != want.from || !errors.Is(err, failing) {
+			t.Errorf("expected a ListenerError for %v, got %v", want, err)
+		}
+	}
+}
+
+func TestNotifierWithoutListeners(t *testing.T) {
+	var n Notifier
+	n.Notify(StateClosed, StateOpen) // The zero value is ready to use.
+	if n.running || len(n.pending) != 0 {
+		t.Error("expected nothing to be queued without listeners")
+	}
+}
@@ -0,0 +1,271 @@
+// Package memory implements a circuit breaker kept in the memory of a single process, counting outcomes over
+// the same kind of window as the Redis realtime implementation.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+	"github.com/christopherdavenport/gocircuit/timeout"
+)
+
+// inspectTransitions is the number of transitions kept for Inspect.
+const inspectTransitions = 10
+
+// Settings configures a circuit breaker created by NewMemoryCircuitBreaker. Zero values select the defaults.
+type Settings struct {
+	Interval    time.Duration // The period of time over which requests are counted. Defaults to a minute.
+	WindowSize  int           // When positive, only the last WindowSize outcomes are counted regardless of their age, instead of those within Interval.
+	OpenTimeout time.Duration // The period of time after which the circuit breaker transitions from open to half-open. Defaults to a minute.
+
+	ReadyToTrip func(counts gocircuit.Counts) bool // Defaults to more than 5 consecutive failures.
+	// StateListeners are called asynchronously, in order, after every state change. Errors they return are passed
+	// to OnListenerError as a *gocircuit.ListenerError.
+	StateListeners  []gocircuit.StateListener
+	OnListenerError func(err error)
+	// IsSuccessful decides whether an error counts as a success, unless a classifier is given. A caller canceling
+	// the context is neither a success nor a failure.
+	IsSuccessful func(err error) bool
+	// Weight gives the weight of an outcome in the Weighted counts from its error, unless the context sets one
+	// with gocircuit.WithWeight. Defaults to one.
+	Weight func(err error) float64
+
+	SlowCallThreshold time.Duration       // Actions running longer than this publish a slow call event. Zero disables it.
+	PanicMode         gocircuit.PanicMode // What Protect does after a panicking action has been recorded as a failure.
+	// Timeout abandons actions running longer than this with a *gocircuit.TimeoutError, counted as a failure. Zero disables it.
+	Timeout time.Duration
+}
+
+type memoryCircuitBreaker[A any] struct {
+	name     string
+	settings Settings
+	classify gocircuit.Classifier[A]
+	notifier *gocircuit.Notifier
+	events   *gocircuit.EventBus
+
+	mu          sync.Mutex
+	state       gocircuit.State
+	openUntil   time.Time
+	forced      gocircuit.State // StateClosed unless an operator has forced the state.
+	forcedUntil time.Time
+	window      window
+	transitions []gocircuit.Transition // Newest first.
+}
+
+// NewMemoryCircuitBreaker creates a circuit breaker named name kept in memory.
+func NewMemoryCircuitBreaker[A any](name string, settings Settings) gocircuit.ContextCircuitBreaker[A] {
+	return NewMemoryCircuitBreakerWithClassifier[A](name, settings, nil)
+}
+
+// NewMemoryCircuitBreakerWithClassifier creates a circuit breaker deciding outcomes with classify rather than
+// IsSuccessful, so results as well as errors can count as failures.
+func NewMemoryCircuitBreakerWithClassifier[A any](name string, settings Settings, classify gocircuit.Classifier[A]) gocircuit.ContextCircuitBreaker[A] {
+	if settings.Interval <= 0 {
+		settings.Interval = time.Minute
+	}
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = time.Minute
+	}
+	if settings.ReadyToTrip == nil {
+		settings.ReadyToTrip = func(counts gocircuit.Counts) bool {
+			return counts.ConsecutiveFailures > 5
+		}
+	}
+	return &memoryCircuitBreaker[A]{
+		name:     name,
+		settings: settings,
+		classify: classify,
+		notifier: gocircuit.NewNotifier(settings.OnListenerError, settings.StateListeners...),
+		events:   &gocircuit.EventBus{},
+		state:    gocircuit.StateClosed,
+		forced:   gocircuit.StateClosed,
+		window:   window{interval: settings.Interval, size: settings.WindowSize},
+	}
+}
+
+func (cb *memoryCircuitBreaker[A]) Protect(ctx context.Context, action func() (A, error)) (A, error) {
+	return cb.ProtectContext(ctx, func(context.Context) (A, error) {
+		return action()
+	})
+}
+
+func (cb *memoryCircuitBreaker[A]) ProtectContext(ctx context.Context, action func(ctx context.Context) (A, error)) (A, error) {
+	state, err := cb.admit()
+	if err != nil {
+		cb.publish(gocircuit.Event{Kind: gocircuit.EventRejected, State: state, Err: err})
+		var empty A
+		return empty, err
+	}
+
+	run := func() (A, error) {
+		return action(ctx)
+	}
+	if cb.settings.Timeout > 0 {
+		run = func() (A, error) {
+			return timeout.Run(ctx, cb.settings.Timeout, action)
+		}
+	}
+	cb.publish(gocircuit.Event{Kind: gocircuit.EventAdmitted, State: state})
+	start := time.Now()
+	value, err := gocircuit.Recover(run)()
+	duration := time.Since(start)
+	outcome := gocircuit.Classify(ctx, cb.classify, cb.settings.IsSuccessful, value, err)
+	cb.record(state, outcome, gocircuit.Weight(ctx, cb.settings.Weight, err))
+
+	gocircuit.ObserveOutcome(ctx, outcome)
+	kind := gocircuit.EventKindOf(outcome)
+	cb.publish(gocircuit.Event{Kind: kind, State: state, Err: err, Duration: duration})
+	if cb.settings.SlowCallThreshold > 0 && duration > cb.settings.SlowCallThreshold {
+		cb.publish(gocircuit.Event{Kind: gocircuit.EventSlowCall, State: state, Err: err, Duration: duration})
+	}
+	cb.settings.PanicMode.Rethrow(err) // Only once the panic has been recorded as a failure.
+	return value, err
+}
+
+// admit decides whether to run an action, returning the state it runs in, or the state and error it is rejected with.
+func (cb *memoryCircuitBreaker[A]) admit() (gocircuit.State, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	now := time.Now()
+	cb.expire(now)
+
+	switch cb.forced {
+	case gocircuit.StateForcedOpen:
+		return cb.forced, &gocircuit.OpenError{Err: gocircuit.ErrOpen, Until: cb.forcedUntil}
+	case gocircuit.StateForcedClosed:
+		return cb.forced, nil
+	}
+
+	switch cb.state {
+	case gocircuit.StateClosed:
+		if !cb.settings.ReadyToTrip(cb.window.counts) {
+			return cb.state, nil
+		}
+		cb.open(gocircuit.ReasonReadyToTrip, cb.settings.OpenTimeout, now)
+	case gocircuit.StateOpen:
+		if !now.Before(cb.openUntil) {
+			cb.transition(cb.state, gocircuit.StateHalfOpen, gocircuit.ReasonOpenTimeout, now)
+			cb.state = gocircuit.StateHalfOpen
+			return cb.state, nil
+		}
+	}
+	var until time.Time
+	if cb.state == gocircuit.StateOpen { // Half-open admits again once the probe finishes, which is unknown.
+		until = cb.openUntil
+	}
+	return cb.state, &gocircuit.OpenError{Err: gocircuit.ErrOpen, Until: until}
+}
+
+// record counts the outcome of an action admitted in state.
+func (cb *memoryCircuitBreaker[A]) record(state gocircuit.State, outcome gocircuit.Outcome, weight float64) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	now := time.Now()
+	switch {
+	case state == gocircuit.StateClosed && outcome != gocircuit.OutcomeIgnore:
+		cb.window.add(record{at: now, success: outcome == gocircuit.OutcomeSuccess, weight: weight})
+	case state == gocircuit.StateHalfOpen && cb.state == gocircuit.StateHalfOpen: // The probe, unless reset since.
+		switch outcome {
+		case gocircuit.OutcomeSuccess:
+			cb.transition(cb.state, gocircuit.StateClosed, gocircuit.ReasonProbeSucceeded, now)
+			cb.state = gocircuit.StateClosed
+			cb.window.reset()
+		case gocircuit.OutcomeFailure:
+			cb.open(gocircuit.ReasonProbeFailed, cb.settings.OpenTimeout, now)
+		default: // The probe was inconclusive, so reopen ready for the next caller to probe.
+			cb.open(gocircuit.ReasonProbeIgnored, 0, now)
+		}
+	}
+}
+
+// expire drops the outcomes and the forced state that have expired by now. It must be called with mu held.
+func (cb *memoryCircuitBreaker[A]) expire(now time.Time) {
+	cb.window.expire(now)
+	if !cb.forcedUntil.IsZero() && !now.Before(cb.forcedUntil) {
+		cb.forced, cb.forcedUntil = gocircuit.StateClosed, time.Time{}
+	}
+}
+
+func (cb *memoryCircuitBreaker[A]) open(reason string, openTimeout time.Duration, now time.Time) {
+	cb.transition(cb.state, gocircuit.StateOpen, reason, now)
+	cb.state, cb.openUntil = gocircuit.StateOpen, now.Add(openTimeout)
+}
+
+// transition records a state change and notifies listeners and subscribers of it. It must be called with mu held.
+func (cb *memoryCircuitBreaker[A]) transition(from gocircuit.State, to gocircuit.State, reason string, now time.Time) {
+	t := gocircuit.Transition{From: from, To: to, Counts: cb.window.counts, Reason: reason, Time: now}
+	cb.transitions = append([]gocircuit.Transition{t}, cb.transitions[:min(len(cb.transitions), inspectTransitions-1)]...)
+	if from != to {
+		cb.notifier.Notify(from, to)
+		cb.publish(gocircuit.Event{Kind: gocircuit.EventStateChange, From: from, State: to})
+	}
+}
+
+func (cb *memoryCircuitBreaker[A]) publish(event gocircuit.Event) {
+	event.Policy, event.Breaker = gocircuit.PolicyCircuitBreaker, cb.name
+	cb.events.Publish(event)
+}
+
+func (cb *memoryCircuitBreaker[A]) Inspect(ctx context.Context) (gocircuit.Snapshot, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.expire(time.Now())
+	snapshot := gocircuit.Snapshot{
+		State:       cb.state,
+		Counts:      cb.window.counts,
+		Transitions: append([]gocircuit.Transition(nil), cb.transitions...),
+	}
+	if cb.state == gocircuit.StateOpen {
+		snapshot.OpenUntil = cb.openUntil
+	}
+	if cb.forced != gocircuit.StateClosed {
+		snapshot.State, snapshot.OpenUntil = cb.forced, cb.forcedUntil
+	}
+	return snapshot, nil
+}
+
+func (cb *memoryCircuitBreaker[A]) Force(ctx context.Context, state gocircuit.State, expiry time.Duration) error {
+	if !state.IsForced() {
+		return fmt.Errorf("cannot force state: %s", state)
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	now := time.Now()
+	from := cb.state
+	if cb.forced != gocircuit.StateClosed {
+		from = cb.forced
+	}
+	cb.transition(from, state, gocircuit.ReasonForced, now)
+	cb.forced, cb.forcedUntil = state, time.Time{}
+	if expiry > 0 {
+		cb.forcedUntil = now.Add(expiry)
+	}
+	return nil
+}
+
+func (cb *memoryCircuitBreaker[A]) Reset(ctx context.Context) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	from := cb.state
+	if cb.forced != gocircuit.StateClosed {
+		from = cb.forced
+	}
+	cb.transition(from, gocircuit.StateClosed, gocircuit.ReasonReset, time.Now())
+	cb.state = gocircuit.StateClosed
+	cb.forced, cb.forcedUntil = gocircuit.StateClosed, time.Time{}
+	cb.window.reset()
+	return nil
+}
+
+// AddStateListener registers listener for every state change from now on.
+func (cb *memoryCircuitBreaker[A]) AddStateListener(listener gocircuit.StateListener) {
+	cb.notifier.Add(listener)
+}
+
+// Subscribe returns a subscription to the activity of the circuit breaker, buffering up to buffer events.
+func (cb *memoryCircuitBreaker[A]) Subscribe(buffer int) *gocircuit.Subscription {
+	return cb.events.Subscribe(buffer)
+}
@@ -0,0 +1,153 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+var errFailed = errors.New("failed")
+
+func succeed() (int, error) { return 1, nil }
+func fail() (int, error)    { return 0, errFailed }
+
+func state(t *testing.T, cb gocircuit.CircuitBreaker[int]) gocircuit.State {
+	t.Helper()
+	snapshot, err := cb.(gocircuit.Inspector).Inspect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return snapshot.State
+}
+
+func TestTripAndRecover(t *testing.T) {
+	ctx := context.Background()
+	cb := NewMemoryCircuitBreaker[int]("test", Settings{
+		OpenTimeout: 10 * time.Millisecond,
+		ReadyToTrip: func(counts gocircuit.Counts) bool { return counts.ConsecutiveFailures >= 2 },
+	})
+	for i := 0; i < 2; i++ {
+		if _, err := cb.Protect(ctx, fail); err != errFailed {
+			t.Fatalf("expected the failure, got %v", err)
+		}
+	}
+	_, err := cb.Protect(ctx, succeed)
+	if !errors.Is(err, gocircuit.ErrOpen) {
+		t.Fatalf("expected a rejection, got %v", err)
+	}
+	if retryAfter, ok := gocircuit.RetryAfter(err); !ok || retryAfter > 10*time.Millisecond {
+		t.Errorf("expected a retry-after within the open timeout, got %s", retryAfter)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if _, err := cb.Protect(ctx, fail); err != errFailed {
+		t.Fatalf("expected the failed probe to run, got %v", err)
+	}
+	if s := state(t, cb); s != gocircuit.StateOpen {
+		t.Fatalf("expected a failed probe to reopen, got %s", s)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if _, err := cb.Protect(ctx, succeed); err != nil {
+		t.Fatalf("expected the probe to run, got %v", err)
+	}
+	if s := state(t, cb); s != gocircuit.StateClosed {
+		t.Fatalf("expected a successful probe to close, got %s", s)
+	}
+}
+
+func TestHalfOpenAdmitsOneProbe(t *testing.T) {
+	ctx := context.Background()
+	cb := NewMemoryCircuitBreaker[int]("test", Settings{
+		OpenTimeout: time.Millisecond,
+		ReadyToTrip: func(counts gocircuit.Counts) bool { return counts.TotalFailures > 0 },
+	})
+	_, _ = cb.Protect(ctx, fail)
+	_, _ = cb.Protect(ctx, succeed) // Trips.
+	time.Sleep(time.Millisecond)
+
+	_, err := cb.Protect(ctx, func() (int, error) {
+		if _, err := cb.Protect(ctx, succeed); !errors.Is(err, gocircuit.ErrOpen) {
+			t.Errorf("expected a second request to be rejected while probing, got %v", err)
+		}
+		return 1, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestForceAndReset(t *testing.T) {
+	ctx := context.Background()
+	cb := NewMemoryCircuitBreaker[int]("test", Settings{})
+	events := cb.(gocircuit.EventSource).Subscribe(8)
+	defer events.Close()
+	controller := cb.(gocircuit.Controller)
+
+	if err := controller.Force(ctx, gocircuit.StateForcedOpen, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cb.Protect(ctx, succeed); !errors.Is(err, gocircuit.ErrOpen) {
+		t.Errorf("expected a forced open rejection, got %v", err)
+	}
+	if err := controller.Reset(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cb.Protect(ctx, succeed); err != nil {
+		t.Errorf("expected reset to close, got %v", err)
+	}
+
+	var kinds []gocircuit.EventKind
+	for len(kinds) < 5 {
+		kinds = append(kinds, (<-events.Events()).Kind)
+	}
+	want := []gocircuit.EventKind{gocircuit.EventStateChange, gocircuit.EventRejected, gocircuit.EventStateChange, gocircuit.EventAdmitted, gocircuit.EventSuccess}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatalf("expected events %v, got %v", want, kinds)
+		}
+	}
+}
+
+func TestForcedStateExpires(t *testing.T) {
+	ctx := context.Background()
+	cb := NewMemoryCircuitBreaker[int]("test", Settings{})
+	if err := cb.(gocircuit.Controller).Force(ctx, gocircuit.StateForcedOpen, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	if _, err := cb.Protect(ctx, succeed); err != nil {
+		t.Errorf("expected the forced state to have expired, got %v", err)
+	}
+}
+
+func TestWeightedTrip(t *testing.T) {
+	ctx := context.Background()
+	cb := NewMemoryCircuitBreaker[int]("test", Settings{
+		Weight:      func(err error) float64 { return 3 },
+		ReadyToTrip: func(counts gocircuit.Counts) bool { return counts.Weighted.TotalFailures >= 6 },
+	})
+	_, _ = cb.Protect(ctx, fail)
+	_, _ = cb.Protect(gocircuit.WithWeight(ctx, 1), fail)
+	if s := state(t, cb); s != gocircuit.StateClosed {
+		t.Fatalf("expected weights 3 and 1 not to trip, got %s", s)
+	}
+	_, _ = cb.Protect(ctx, fail)
+	if _, err := cb.Protect(ctx, succeed); !errors.Is(err, gocircuit.ErrOpen) {
+		t.Errorf("expected weights totalling 7 to trip, got %v", err)
+	}
+}
+
+func TestIgnoredOutcomesNotCounted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cb := NewMemoryCircuitBreaker[int]("test", Settings{})
+	_, _ = cb.Protect(ctx, func() (int, error) { return 0, ctx.Err() })
+	snapshot, _ := cb.(gocircuit.Inspector).Inspect(ctx)
+	if snapshot.Counts.Requests != 0 {
+		t.Errorf("expected the caller canceling to be ignored, got %+v", snapshot.Counts)
+	}
+}
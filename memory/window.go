@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+type record struct {
+	at      time.Time
+	success bool
+	weight  float64
+}
+
+// window counts the outcomes recorded within interval, or the last size outcomes if size is positive, kept up to
+// date as outcomes are added and expire.
+type window struct {
+	interval time.Duration
+	size     int
+	records  []record // Oldest first.
+	run      int      // The index of the first record of the current run of successes or failures.
+	counts   gocircuit.Counts
+}
+
+func (w *window) add(r record) {
+	if len(w.records) > 0 && w.records[len(w.records)-1].success != r.success {
+		w.run = len(w.records)
+		if r.success {
+			w.counts.ConsecutiveFailures, w.counts.Weighted.ConsecutiveFailures = 0, 0
+		} else {
+			w.counts.ConsecutiveSuccesses, w.counts.Weighted.ConsecutiveSuccesses = 0, 0
+		}
+	}
+	w.records = append(w.records, r)
+	w.apply(r, 1, true)
+	for w.size > 0 && len(w.records) > w.size {
+		w.drop()
+	}
+}
+
+// expire drops the records older than the interval before now, unless the window holds the last size records.
+func (w *window) expire(now time.Time) {
+	if w.size > 0 {
+		return
+	}
+	start := now.Add(-w.interval)
+	for len(w.records) > 0 && !w.records[0].at.After(start) {
+		w.drop()
+	}
+}
+
+// drop removes the oldest record.
+func (w *window) drop() {
+	w.apply(w.records[0], -1, w.run == 0)
+	w.records = w.records[1:]
+	w.run = max(0, w.run-1)
+}
+
+// apply adds sign times r to the counts, including the consecutive counts if it belongs to the current run.
+func (w *window) apply(r record, sign int64, inRun bool) {
+	c, weight := &w.counts, float64(sign)*r.weight
+	c.Requests += sign
+	c.Weighted.Requests += weight
+	if r.success {
+		c.TotalSuccesses += sign
+		c.Weighted.TotalSuccesses += weight
+		if inRun {
+			c.ConsecutiveSuccesses += sign
+			c.Weighted.ConsecutiveSuccesses += weight
+		}
+	} else {
+		c.TotalFailures += sign
+		c.Weighted.TotalFailures += weight
+		if inRun {
+			c.ConsecutiveFailures += sign
+			c.Weighted.ConsecutiveFailures += weight
+		}
+	}
+}
+
+func (w *window) reset() {
+	w.records, w.run, w.counts = nil, 0, gocircuit.Counts{}
+}
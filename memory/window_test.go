@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+// recount counts records from scratch, for comparison with the counts a window keeps up to date.
+func recount(records []record) gocircuit.Counts {
+	var c gocircuit.Counts
+	for i, r := range records {
+		c.Requests++
+		c.Weighted.Requests += r.weight
+		if r.success {
+			c.TotalSuccesses++
+			c.Weighted.TotalSuccesses += r.weight
+		} else {
+			c.TotalFailures++
+			c.Weighted.TotalFailures += r.weight
+		}
+		if i > 0 && records[i-1].success != r.success {
+			c.ConsecutiveSuccesses, c.ConsecutiveFailures = 0, 0
+			c.Weighted.ConsecutiveSuccesses, c.Weighted.ConsecutiveFailures = 0, 0
+		}
+		if r.success {
+			c.ConsecutiveSuccesses++
+			c.Weighted.ConsecutiveSuccesses += r.weight
+		} else {
+			c.ConsecutiveFailures++
+			c.Weighted.ConsecutiveFailures += r.weight
+		}
+	}
+	return c
+}
+
+func TestWindowMatchesRecount(t *testing.T) {
+	for _, size := range []int{0, 1, 3, 10} {
+		rng := rand.New(rand.NewPCG(1, uint64(size)))
+		w := window{interval: 10 * time.Second, size: size}
+		now := time.Unix(0, 0)
+		for i := 0; i < 1000; i++ {
+			now = now.Add(time.Duration(rng.IntN(3000)) * time.Millisecond)
+			w.expire(now)
+			w.add(record{at: now, success: rng.IntN(3) > 0, weight: float64(rng.IntN(4))})
+
+			if size > 0 && len(w.records) > size {
+				t.Fatalf("size %d: kept %d records", size, len(w.records))
+			}
+			if want := recount(w.records); w.counts != want {
+				t.Fatalf("size %d, step %d: expected %+v, got %+v", size, i, want, w.counts)
+			}
+		}
+	}
+}
+
+func TestWindowSizeIgnoresAge(t *testing.T) {
+	w := window{interval: time.Second, size: 2}
+	start := time.Unix(0, 0)
+	w.add(record{at: start, success: false, weight: 1})
+	w.add(record{at: start, success: false, weight: 1})
+	w.expire(start.Add(time.Hour))
+	if w.counts.ConsecutiveFailures != 2 {
+		t.Errorf("expected the last 2 outcomes to be kept regardless of age, got %+v", w.counts)
+	}
+}
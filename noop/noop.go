@@ -2,10 +2,35 @@ package noop
 
 import (
 	"context"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
 )
 
-type NoopCircuitBreaker[A any] struct{}
+// NoopCircuitBreaker runs every action. Its zero value publishes no events,
+// use NewNoopCircuitBreaker for one that does.
+type NoopCircuitBreaker[A any] struct {
+	name   string
+	events *gocircuit.EventBus
+}
+
+func NewNoopCircuitBreaker[A any](name string) NoopCircuitBreaker[A] {
+	return NoopCircuitBreaker[A]{name: name, events: &gocircuit.EventBus{}}
+}
 
 func (n NoopCircuitBreaker[A]) Protect(ctx context.Context, action func() (A, error)) (A, error) {
-	return action()
+	if n.events == nil {
+		return action()
+	}
+	n.events.Publish(gocircuit.Event{Kind: gocircuit.EventAdmitted, Policy: gocircuit.PolicyCircuitBreaker, Breaker: n.name})
+	start := time.Now()
+	value, err := action()
+	kind := gocircuit.EventKindOf(gocircuit.Classify(ctx, nil, nil, value, err))
+	n.events.Publish(gocircuit.Event{Kind: kind, Policy: gocircuit.PolicyCircuitBreaker, Breaker: n.name, Err: err, Duration: time.Since(start)})
+	return value, err
+}
+
+// Subscribe returns a subscription to the actions run, buffering up to buffer events.
+func (n NoopCircuitBreaker[A]) Subscribe(buffer int) *gocircuit.Subscription {
+	return n.events.Subscribe(buffer)
 }
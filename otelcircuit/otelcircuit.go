@@ -0,0 +1,238 @@
+// Otelcircuit traces and measures circuit breakers with OpenTelemetry.
+
+package otelcircuit
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/christopherdavenport/gocircuit/otelcircuit"
+
+// eventBuffer is the number of undelivered events buffered for the wrapped circuit breaker.
+const eventBuffer = 1024
+
+// Attribute keys set on spans and measurements.
+const (
+	NameKey    = attribute.Key("gocircuit.breaker.name")
+	StateKey   = attribute.Key("gocircuit.breaker.state")
+	OutcomeKey = attribute.Key("gocircuit.outcome")
+	FromKey    = attribute.Key("gocircuit.state.from")
+	ToKey      = attribute.Key("gocircuit.state.to")
+)
+
+// Outcomes recorded under OutcomeKey.
+const (
+	OutcomeSuccess  = "success"
+	OutcomeFailure  = "failure"
+	OutcomeIgnored  = "ignored" // Counted as neither, such as an action canceled by the caller.
+	OutcomeRejected = "rejected"
+)
+
+type Settings struct {
+	TracerProvider trace.TracerProvider // Defaults to the global TracerProvider.
+	MeterProvider  metric.MeterProvider // Defaults to the global MeterProvider.
+}
+
+// Breaker decorates a circuit breaker with a span per Protect and OpenTelemetry metrics.
+type Breaker[A any] struct {
+	name    string
+	circuit gocircuit.CircuitBreaker[A]
+	state   atomic.Int64 // The last known gocircuit.State.
+	// subscription delivers the events of circuit, from which requests and transitions are recorded. Nil if
+	// circuit publishes none.
+	subscription *gocircuit.Subscription
+
+	tracer      trace.Tracer
+	requests    metric.Int64Counter
+	duration    metric.Float64Histogram
+	transitions metric.Int64Counter
+}
+
+// New returns a Breaker tracing and measuring circuit under name.
+//
+// If circuit implements gocircuit.Inspector it is used for the initial state. If circuit is a
+// gocircuit.EventSource publishing events, as the circuit breakers in this module do, requests, their outcomes
+// and latencies, and transitions are recorded from its events, so they agree with what circuit counted. The
+// state recorded at admission is the one its latest event was admitted or rejected in, or changed to,
+// including state changes made by other instances sharing its state, as soon as this instance sees them.
+// Events are delivered asynchronously, so the state of a span may lag behind by a request, and a state change
+// is only added to a span if it was delivered before the span ended. Call Close to stop recording them.
+//
+// Otherwise requests are recorded as they return, and StateChange() should be passed as the OnStateChange
+// setting of circuit.
+//
+// The outcome of a span is the one circuit observed with gocircuit.ObserveOutcome, as the circuit breakers in
+// this module do. Otherwise any error is a failure, except the caller canceling the context, which is ignored.
+func New[A any](name string, circuit gocircuit.CircuitBreaker[A], settings Settings) (*Breaker[A], error) {
+	tracerProvider := settings.TracerProvider
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+	meterProvider := settings.MeterProvider
+	if meterProvider == nil {
+		meterProvider = otel.GetMeterProvider()
+	}
+	meter := meterProvider.Meter(instrumentationName)
+
+	requests, err := meter.Int64Counter("gocircuit.requests",
+		metric.WithDescription("Requests made through the circuit breaker, by outcome."),
+		metric.WithUnit("{request}"))
+	if err != nil {
+		return nil, err
+	}
+	duration, err := meter.Float64Histogram("gocircuit.action.duration",
+		metric.WithDescription("Latency of the actions run by the circuit breaker."),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	transitions, err := meter.Int64Counter("gocircuit.transitions",
+		metric.WithDescription("State transitions of the circuit breaker."),
+		metric.WithUnit("{transition}"))
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Breaker[A]{
+		name:        name,
+		circuit:     circuit,
+		tracer:      tracerProvider.Tracer(instrumentationName),
+		requests:    requests,
+		duration:    duration,
+		transitions: transitions,
+	}
+	if inspector, ok := gocircuit.AsInspector(circuit); ok {
+		snapshot, err := inspector.Inspect(context.Background())
+		if err == nil {
+			b.state.Store(int64(snapshot.State))
+		}
+	}
+	if source, ok := gocircuit.AsEventSource(circuit); ok {
+		subscription := source.Subscribe(eventBuffer)
+		if !subscription.Closed() {
+			b.subscription = subscription
+			go func() {
+				for event := range subscription.Events() {
+					b.event(event)
+				}
+			}()
+		}
+	}
+	return b, nil
+}
+
+// Close stops recording the events of the wrapped circuit breaker.
+func (b *Breaker[A]) Close() {
+	if b.subscription != nil {
+		b.subscription.Close()
+	}
+}
+
+// event records an event of the wrapped circuit breaker.
+func (b *Breaker[A]) event(event gocircuit.Event) {
+	switch event.Kind {
+	case gocircuit.EventAdmitted:
+		b.state.Store(int64(event.State))
+	case gocircuit.EventRejected:
+		b.state.Store(int64(event.State))
+		b.record(context.Background(), OutcomeRejected, 0)
+	case gocircuit.EventSuccess:
+		b.record(context.Background(), OutcomeSuccess, event.Duration)
+	case gocircuit.EventFailure:
+		b.record(context.Background(), OutcomeFailure, event.Duration)
+	case gocircuit.EventIgnored:
+		b.record(context.Background(), OutcomeIgnored, event.Duration)
+	case gocircuit.EventStateChange:
+		_ = b.StateChange()(event.From, event.State)
+	}
+}
+
+// record counts a request with outcome, and the latency of its action unless it was rejected.
+func (b *Breaker[A]) record(ctx context.Context, outcome string, latency time.Duration) {
+	attributes := metric.WithAttributes(NameKey.String(b.name), OutcomeKey.String(outcome))
+	b.requests.Add(ctx, 1, attributes)
+	if outcome != OutcomeRejected {
+		b.duration.Record(ctx, latency.Seconds(), attributes)
+	}
+}
+
+// StateChange returns a function suitable for the OnStateChange setting of a wrapped circuit breaker that
+// publishes no events, keeping the recorded state current and counting transitions.
+func (b *Breaker[A]) StateChange() func(old gocircuit.State, new gocircuit.State) error {
+	return func(old gocircuit.State, new gocircuit.State) error {
+		b.state.Store(int64(new))
+		b.transitions.Add(context.Background(), 1, metric.WithAttributes(
+			NameKey.String(b.name),
+			FromKey.String(old.String()),
+			ToKey.String(new.String()),
+		))
+		return nil
+	}
+}
+
+func (b *Breaker[A]) Protect(ctx context.Context, action func() (A, error)) (A, error) {
+	admitted := gocircuit.State(b.state.Load())
+	ctx, span := b.tracer.Start(ctx, "gocircuit.Protect", trace.WithAttributes(
+		NameKey.String(b.name),
+		StateKey.String(admitted.String()),
+	))
+	defer span.End()
+
+	var observed atomic.Pointer[gocircuit.Outcome]
+	observe := gocircuit.WithOutcomeObserver(ctx, func(outcome gocircuit.Outcome) {
+		observed.Store(&outcome)
+	})
+	start := time.Now()
+	value, err, rejected := gocircuit.ProtectObserved(b.circuit, observe, func(context.Context) (A, error) {
+		return action()
+	})
+	latency := time.Since(start)
+
+	var outcome string
+	if rejected {
+		outcome = OutcomeRejected
+		span.AddEvent("gocircuit.rejected")
+	} else if o := observed.Load(); o != nil {
+		outcome = outcomes[*o]
+	} else {
+		outcome = outcomes[gocircuit.Classify(ctx, nil, nil, value, err)]
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+	if err != nil && (outcome == OutcomeFailure || outcome == OutcomeRejected) {
+		span.SetStatus(codes.Error, err.Error())
+	}
+	if current := gocircuit.State(b.state.Load()); current != admitted {
+		span.AddEvent("gocircuit.state_change", trace.WithAttributes(
+			FromKey.String(admitted.String()),
+			ToKey.String(current.String()),
+		))
+	}
+	span.SetAttributes(OutcomeKey.String(outcome))
+
+	if b.subscription == nil {
+		b.record(ctx, outcome, latency)
+	}
+	return value, err
+}
+
+// outcomes are the values recorded under OutcomeKey for each outcome of an action.
+var outcomes = map[gocircuit.Outcome]string{
+	gocircuit.OutcomeSuccess: OutcomeSuccess,
+	gocircuit.OutcomeFailure: OutcomeFailure,
+	gocircuit.OutcomeIgnore:  OutcomeIgnored,
+}
+
+func (b *Breaker[A]) Unwrap() any {
+	return b.circuit
+}
@@ -0,0 +1,186 @@
+package otelcircuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+	"github.com/christopherdavenport/gocircuit/memory"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestBreaker(t *testing.T) (*Breaker[int], gocircuit.ContextCircuitBreaker[int], *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	t.Helper()
+	circuit := memory.NewMemoryCircuitBreaker[int]("test", memory.Settings{
+		ReadyToTrip: func(counts gocircuit.Counts) bool {
+			return counts.ConsecutiveFailures >= 2
+		},
+		IsSuccessful: func(err error) bool { return err.Error() == "not found" },
+	})
+	recorder := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	b, err := New[int]("test", circuit, Settings{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+		MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(b.Close)
+	return b, circuit, recorder, reader
+}
+
+// waitForState polls until the events of the wrapped breaker have been delivered.
+func waitForState(t *testing.T, b *Breaker[int], state gocircuit.State) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for gocircuit.State(b.state.Load()) != state {
+		if time.Now().After(deadline) {
+			t.Fatalf("state = %s, want %s", gocircuit.State(b.state.Load()), state)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// sum returns the sum of the named counter over the data points with every attribute in attributes.
+func sum(t *testing.T, reader *sdkmetric.ManualReader, name string, attributes ...attribute.KeyValue) int64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	var total int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+		points:
+			for _, point := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				for _, kv := range attributes {
+					if v, ok := point.Attributes.Value(kv.Key); !ok || v != kv.Value {
+						continue points
+					}
+				}
+				total += point.Value
+			}
+		}
+	}
+	return total
+}
+
+// transitions returns the transitions counted from one state to another.
+func transitions(t *testing.T, reader *sdkmetric.ManualReader, from gocircuit.State, to gocircuit.State) int64 {
+	t.Helper()
+	return sum(t, reader, "gocircuit.transitions", FromKey.String(from.String()), ToKey.String(to.String()))
+}
+
+// spanAttribute returns the value of key on span.
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) string {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value.AsString()
+		}
+	}
+	return ""
+}
+
+func TestStateFromEvents(t *testing.T) {
+	b, _, recorder, reader := newTestBreaker(t)
+	ctx := context.Background()
+	failure := errors.New("failure")
+
+	for i := 0; i < 2; i++ {
+		_, _ = b.Protect(ctx, func() (int, error) { return 0, failure })
+	}
+	// The third call trips the breaker at admission.
+	if _, err := b.Protect(ctx, func() (int, error) { return 0, nil }); !errors.Is(err, gocircuit.ErrOpen) {
+		t.Fatalf("err = %v, want open", err)
+	}
+	waitForState(t, b, gocircuit.StateOpen)
+	if _, err := b.Protect(ctx, func() (int, error) { return 0, nil }); !errors.Is(err, gocircuit.ErrOpen) {
+		t.Fatalf("err = %v, want open", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 4 {
+		t.Fatalf("%d spans, want 4", len(spans))
+	}
+	if state := spanAttribute(spans[3], StateKey); state != gocircuit.StateOpen.String() {
+		t.Errorf("state = %s, want %s", state, gocircuit.StateOpen)
+	}
+	if outcome := spanAttribute(spans[3], OutcomeKey); outcome != OutcomeRejected {
+		t.Errorf("outcome = %s, want %s", outcome, OutcomeRejected)
+	}
+	if got := transitions(t, reader, gocircuit.StateClosed, gocircuit.StateOpen); got != 1 {
+		t.Errorf("closed to open transitions = %d, want 1", got)
+	}
+}
+
+func TestStateChangesOutsideProtect(t *testing.T) {
+	b, circuit, _, reader := newTestBreaker(t)
+	ctx := context.Background()
+
+	if err := circuit.(gocircuit.Controller).Force(ctx, gocircuit.StateForcedOpen, 0); err != nil {
+		t.Fatal(err)
+	}
+	waitForState(t, b, gocircuit.StateForcedOpen)
+	if err := circuit.(gocircuit.Controller).Reset(ctx); err != nil {
+		t.Fatal(err)
+	}
+	waitForState(t, b, gocircuit.StateClosed)
+
+	if got := transitions(t, reader, gocircuit.StateClosed, gocircuit.StateForcedOpen); got != 1 {
+		t.Errorf("closed to forced open transitions = %d, want 1", got)
+	}
+	if got := transitions(t, reader, gocircuit.StateForcedOpen, gocircuit.StateClosed); got != 1 {
+		t.Errorf("forced open to closed transitions = %d, want 1", got)
+	}
+}
+
+func TestOutcomeAsBreakerCounts(t *testing.T) {
+	b, _, recorder, reader := newTestBreaker(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _ = b.Protect(context.Background(), func() (int, error) { return 0, errors.New("not found") })
+	_, _ = b.Protect(ctx, func() (int, error) { return 0, ctx.Err() })
+	_, _ = b.Protect(context.Background(), func() (int, error) { return 0, errors.New("failed") })
+
+	spans := recorder.Ended()
+	for i, want := range []string{OutcomeSuccess, OutcomeIgnored, OutcomeFailure} {
+		if outcome := spanAttribute(spans[i], OutcomeKey); outcome != want {
+			t.Errorf("span %d: outcome = %s, want %s", i, outcome, want)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for sum(t, reader, "gocircuit.requests") < 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("requests not recorded")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	for _, outcome := range []string{OutcomeSuccess, OutcomeIgnored, OutcomeFailure} {
+		if got := sum(t, reader, "gocircuit.requests", OutcomeKey.String(outcome)); got != 1 {
+			t.Errorf("%s requests = %d, want 1", outcome, got)
+		}
+	}
+}
+
+func TestCloseStopsRecording(t *testing.T) {
+	b, _, _, reader := newTestBreaker(t)
+	b.Close()
+
+	_, _ = b.Protect(context.Background(), func() (int, error) { return 0, nil })
+	time.Sleep(10 * time.Millisecond)
+	if got := sum(t, reader, "gocircuit.requests"); got != 0 {
+		t.Errorf("requests = %d after Close, want 0", got)
+	}
+}
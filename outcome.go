@@ -0,0 +1,79 @@
+package gocircuit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Outcome is how the result of an action counts towards the state of a circuit breaker.
+type Outcome int
+
+const (
+	OutcomeSuccess Outcome = iota
+	OutcomeFailure
+	OutcomeIgnore // Counted as neither a success nor a failure.
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeSuccess:
+		return "success"
+	case OutcomeFailure:
+		return "failure"
+	case OutcomeIgnore:
+		return "ignore"
+	default:
+		return fmt.Sprintf("unknown outcome: %d", int(o))
+	}
+}
+
+// Classifier decides the outcome of an action from both its result and its error,
+// for example to count an HTTP response with a 503 status as a failure.
+type Classifier[A any] func(value A, err error) Outcome
+
+type observerKey struct{}
+
+// WithOutcomeObserver returns a context under which the circuit breakers and adaptive throttles in this module call
+// observe with the outcome they count for the action protected with it, the same one they publish as an event, so
+// that decorators can attribute it to the call. When several policies in a chain count an outcome, the outermost is
+// observed last.
+func WithOutcomeObserver(ctx context.Context, observe func(outcome Outcome)) context.Context {
+	return context.WithValue(ctx, observerKey{}, observe)
+}
+
+// ObserveOutcome passes outcome to the observer set on ctx by WithOutcomeObserver, if any.
+func ObserveOutcome(ctx context.Context, outcome Outcome) {
+	if observe, ok := ctx.Value(observerKey{}).(func(outcome Outcome)); ok {
+		observe(outcome)
+	}
+}
+
+// CanceledByCaller reports whether err is the result of the caller canceling ctx,
+// rather than a problem with whatever the action called.
+func CanceledByCaller(ctx context.Context, err error) bool {
+	return errors.Is(err, context.Canceled) && ctx.Err() != nil
+}
+
+// Classify decides the outcome of an action run on behalf of ctx.
+//
+// A panic recovered as a *PanicError and a *TimeoutError are always failures. Otherwise a non-nil classifier decides alone.
+// Without one the caller canceling ctx is ignored, and the error is a success if it is nil or
+// isSuccessful accepts it, and a failure if not. A nil isSuccessful accepts only nil errors.
+func Classify[A any](ctx context.Context, classifier Classifier[A], isSuccessful func(err error) bool, value A, err error) Outcome {
+	var panicErr *PanicError
+	var timeoutErr *TimeoutError
+	if errors.As(err, &panicErr) || errors.As(err, &timeoutErr) {
+		return OutcomeFailure
+	}
+	if classifier != nil {
+		return classifier(value, err)
+	}
+	if CanceledByCaller(ctx, err) {
+		return OutcomeIgnore
+	}
+	if err == nil || (isSuccessful != nil && isSuccessful(err)) {
+		return OutcomeSuccess
+	}
+	return OutcomeFailure
+}
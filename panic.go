@@ -0,0 +1,46 @@
+package gocircuit
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is a panic recovered from a protected action.
+type PanicError struct {
+	Value any
+	Stack []byte // The stack of the panicking goroutine.
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("protected action panicked: %v", e.Value)
+}
+
+// PanicMode decides what happens after a panicking action has been counted as a failure.
+type PanicMode int
+
+const (
+	PanicRepanic     PanicMode = iota // Panic again with the recovered value.
+	PanicReturnError                  // Return a *PanicError from Protect.
+)
+
+// Recover wraps action so that a panic is returned as a *PanicError instead.
+func Recover[A any](action func() (A, error)) func() (A, error) {
+	return func() (value A, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				var empty A
+				value, err = empty, &PanicError{Value: r, Stack: debug.Stack()}
+			}
+		}()
+		return action()
+	}
+}
+
+// Rethrow panics with the recovered value if err holds a *PanicError and the mode is PanicRepanic.
+func (m PanicMode) Rethrow(err error) {
+	var panicErr *PanicError
+	if m == PanicRepanic && errors.As(err, &panicErr) {
+		panic(panicErr.Value)
+	}
+}
@@ -0,0 +1,307 @@
+// Promcircuit records per-breaker metrics and exposes them in the
+// Prometheus text exposition format.
+
+package promcircuit
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+// DefaultBuckets are the latency histogram buckets, in seconds, used when none are given to NewCollector.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// eventBuffer is the number of undelivered events buffered for each instrumented breaker.
+const eventBuffer = 1024
+
+// The states reported by the state gauge.
+var states = []gocircuit.State{
+	gocircuit.StateClosed,
+	gocircuit.StateHalfOpen,
+	gocircuit.StateOpen,
+	gocircuit.StateForcedOpen,
+	gocircuit.StateForcedClosed,
+}
+
+// Collector holds the metrics of every instrumented circuit breaker.
+// It implements http.Handler, serving the metrics in the Prometheus text exposition format.
+type Collector struct {
+	buckets []float64
+
+	mu       sync.Mutex
+	breakers map[string]*breakerMetrics
+}
+
+type transition struct {
+	from gocircuit.State
+	to   gocircuit.State
+}
+
+type breakerMetrics struct {
+	inspector     gocircuit.Inspector       // Used to refresh the state on each scrape, may be nil.
+	sources       []gocircuit.EventSource   // The breakers whose events are recorded.
+	subscriptions []*gocircuit.Subscription // The subscriptions to sources.
+
+	state       gocircuit.State
+	transitions map[transition]uint64
+	requests    uint64
+	successes   uint64
+	failures    uint64
+	ignored     uint64
+	rejections  uint64
+
+	latencyBuckets []uint64 // Cumulative counts per bucket.
+	latencySum     float64
+	latencyCount   uint64
+}
+
+// NewCollector returns an empty Collector whose latency histograms use buckets, or DefaultBuckets if none are given.
+func NewCollector(buckets ...float64) *Collector {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Collector{
+		buckets:  buckets,
+		breakers: map[string]*breakerMetrics{},
+	}
+}
+
+// breaker returns the metrics of the named breaker, creating them if necessary. c.mu must be held.
+func (c *Collector) breaker(name string) *breakerMetrics {
+	b, ok := c.breakers[name]
+	if !ok {
+		b = &breakerMetrics{
+			transitions:    map[transition]uint64{},
+			latencyBuckets: make([]uint64, len(c.buckets)),
+		}
+		c.breakers[name] = b
+	}
+	return b
+}
+
+// StateChange returns a function suitable for the OnStateChange setting of the named breaker,
+// which updates its state gauge and transitions counter.
+func (c *Collector) StateChange(name string) func(old gocircuit.State, new gocircuit.State) error {
+	return func(old gocircuit.State, new gocircuit.State) error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		b := c.breaker(name)
+		b.state = new
+		b.transitions[transition{from: old, to: new}]++
+		return nil
+	}
+}
+
+// subscribe records the events of source under name, unless they already are. It reports whether source
+// delivers any events, which it does not if it returns a closed subscription.
+func (c *Collector) subscribe(name string, source gocircuit.EventSource) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b := c.breaker(name)
+	for i, s := range b.sources {
+		if s == source {
+			return !b.subscriptions[i].Closed()
+		}
+	}
+	subscription := source.Subscribe(eventBuffer)
+	if subscription.Closed() {
+		return false
+	}
+	b.sources = append(b.sources, source)
+	b.subscriptions = append(b.subscriptions, subscription)
+	go func() {
+		for event := range subscription.Events() {
+			c.event(name, event)
+		}
+	}()
+	return true
+}
+
+// event records an event published by the named breaker.
+func (c *Collector) event(name string, event gocircuit.Event) {
+	switch event.Kind {
+	case gocircuit.EventRejected, gocircuit.EventSuccess, gocircuit.EventFailure, gocircuit.EventIgnored:
+		c.observe(name, event.Kind, event.Duration)
+	case gocircuit.EventStateChange:
+		_ = c.StateChange(name)(event.From, event.State)
+	}
+}
+
+// observe records a request with the outcome kind, and the latency of its action unless it was rejected.
+func (c *Collector) observe(name string, kind gocircuit.EventKind, latency time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b := c.breaker(name)
+	b.requests++
+	switch kind {
+	case gocircuit.EventRejected:
+		b.rejections++
+		return
+	case gocircuit.EventFailure:
+		b.failures++
+	case gocircuit.EventIgnored:
+		b.ignored++
+	default:
+		b.successes++
+	}
+	seconds := latency.Seconds()
+	for i, bound := range c.buckets {
+		if seconds <= bound {
+			b.latencyBuckets[i]++
+		}
+	}
+	b.latencySum += seconds
+	b.latencyCount++
+}
+
+// refresh updates the state gauges from every breaker that can be inspected.
+func (c *Collector) refresh(ctx context.Context) {
+	c.mu.Lock()
+	inspectors := map[string]gocircuit.Inspector{}
+	for name, b := range c.breakers {
+		if b.inspector != nil {
+			inspectors[name] = b.inspector
+		}
+	}
+	c.mu.Unlock()
+
+	for name, inspector := range inspectors {
+		snapshot, err := inspector.Inspect(ctx)
+		if err != nil {
+			continue // Keep the last known state.
+		}
+		c.mu.Lock()
+		c.breaker(name).state = snapshot.State
+		c.mu.Unlock()
+	}
+}
+
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.refresh(r.Context())
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = c.WriteTo(w)
+}
+
+// WriteTo writes every metric to w in the Prometheus text exposition format.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	names := make([]string, 0, len(c.breakers))
+	for name := range c.breakers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	header := func(metric string, kind string, help string) {
+		fmt.Fprintf(cw, "# HELP %s %s\n# TYPE %s %s\n", metric, help, metric, kind)
+	}
+
+	header("gocircuit_state", "gauge", "Whether the circuit breaker is in the given state.")
+	for _, name := range names {
+		for _, state := range states {
+			value := 0
+			if c.breakers[name].state == state {
+				value = 1
+			}
+			fmt.Fprintf(cw, "gocircuit_state{breaker=%s,state=%s} %d\n", quote(name), quote(state.String()), value)
+		}
+	}
+
+	header("gocircuit_transitions_total", "counter", "State transitions of the circuit breaker.")
+	for _, name := range names {
+		transitions := c.breakers[name].transitions
+		keys := make([]transition, 0, len(transitions))
+		for t := range transitions {
+			keys = append(keys, t)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].from != keys[j].from {
+				return keys[i].from < keys[j].from
+			}
+			return keys[i].to < keys[j].to
+		})
+		for _, t := range keys {
+			fmt.Fprintf(cw, "gocircuit_transitions_total{breaker=%s,from=%s,to=%s} %d\n", quote(name), quote(t.from.String()), quote(t.to.String()), transitions[t])
+		}
+	}
+
+	for _, counter := range []struct {
+		metric string
+		help   string
+		value  func(b *breakerMetrics) uint64
+	}{
+		{"gocircuit_requests_total", "Requests made through the circuit breaker.", func(b *breakerMetrics) uint64 { return b.requests }},
+		{"gocircuit_successes_total", "Actions the circuit breaker counted as successes.", func(b *breakerMetrics) uint64 { return b.successes }},
+		{"gocircuit_failures_total", "Actions the circuit breaker counted as failures.", func(b *breakerMetrics) uint64 { return b.failures }},
+		{"gocircuit_ignored_total", "Actions the circuit breaker counted as neither, such as those canceled by the caller.", func(b *breakerMetrics) uint64 { return b.ignored }},
+		{"gocircuit_rejections_total", "Requests rejected without running the action.", func(b *breakerMetrics) uint64 { return b.rejections }},
+		{"gocircuit_events_dropped_total", "Events of the circuit breaker dropped before they could be recorded.", func(b *breakerMetrics) uint64 {
+			var dropped uint64
+			for _, s := range b.subscriptions {
+				dropped += s.Dropped()
+			}
+			return dropped
+		}},
+	} {
+		header(counter.metric, "counter", counter.help)
+		for _, name := range names {
+			fmt.Fprintf(cw, "%s{breaker=%s} %d\n", counter.metric, quote(name), counter.value(c.breakers[name]))
+		}
+	}
+
+	header("gocircuit_action_duration_seconds", "histogram", "Latency of the actions run by the circuit breaker.")
+	for _, name := range names {
+		b := c.breakers[name]
+		for i, bound := range c.buckets {
+			fmt.Fprintf(cw, "gocircuit_action_duration_seconds_bucket{breaker=%s,le=%s} %d\n", quote(name), quote(formatFloat(bound)), b.latencyBuckets[i])
+		}
+		fmt.Fprintf(cw, "gocircuit_action_duration_seconds_bucket{breaker=%s,le=\"+Inf\"} %d\n", quote(name), b.latencyCount)
+		fmt.Fprintf(cw, "gocircuit_action_duration_seconds_sum{breaker=%s} %s\n", quote(name), formatFloat(b.latencySum))
+		fmt.Fprintf(cw, "gocircuit_action_duration_seconds_count{breaker=%s} %d\n", quote(name), b.latencyCount)
+	}
+
+	if err := cw.w.Flush(); err != nil && cw.err == nil {
+		cw.err = err
+	}
+	return cw.n, cw.err
+}
+
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func quote(label string) string {
+	return `"` + labelEscaper.Replace(label) + `"`
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,69 @@
+package promcircuit
+
+import (
+	"context"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+type instrumented[A any] struct {
+	name      string
+	collector *Collector
+	circuit   gocircuit.CircuitBreaker[A]
+	events    bool // Whether requests are recorded from the events of circuit.
+}
+
+// Instrument returns a circuit breaker recording the requests made through circuit in collector under name.
+//
+// If circuit is a gocircuit.EventSource publishing events, as the circuit breakers in this module do, requests,
+// outcomes, latencies and transitions are recorded from its events, so they agree with what circuit counted,
+// including requests made without going through the returned circuit breaker. Instrumenting the same circuit
+// again records its events once.
+//
+// Otherwise, as for the zero value of noop.NoopCircuitBreaker, a request is counted as rejected when circuit
+// returns an error without running the action, other than a *gocircuit.TimeoutError, and as a failure when it
+// returns any other error. Latency is then measured around the whole call. Pass collector.StateChange(name) as
+// its OnStateChange setting to record transitions.
+//
+// If circuit implements gocircuit.Inspector its state gauge is refreshed on every scrape.
+func Instrument[A any](collector *Collector, name string, circuit gocircuit.CircuitBreaker[A]) gocircuit.CircuitBreaker[A] {
+	collector.mu.Lock()
+	b := collector.breaker(name)
+	b.inspector, _ = gocircuit.AsInspector(circuit)
+	collector.mu.Unlock()
+
+	source, events := gocircuit.AsEventSource(circuit)
+	if events {
+		events = collector.subscribe(name, source)
+	}
+	return &instrumented[A]{
+		name:      name,
+		collector: collector,
+		circuit:   circuit,
+		events:    events,
+	}
+}
+
+func (i *instrumented[A]) Protect(ctx context.Context, action func() (A, error)) (A, error) {
+	if i.events {
+		return i.circuit.Protect(ctx, action)
+	}
+	start := time.Now()
+	value, err, rejected := gocircuit.ProtectObserved(i.circuit, ctx, func(context.Context) (A, error) {
+		return action()
+	})
+	kind := gocircuit.EventSuccess
+	switch {
+	case rejected:
+		kind = gocircuit.EventRejected
+	case err != nil:
+		kind = gocircuit.EventFailure
+	}
+	i.collector.observe(i.name, kind, time.Since(start))
+	return value, err
+}
+
+func (i *instrumented[A]) Unwrap() any {
+	return i.circuit
+}
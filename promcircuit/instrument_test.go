@@ -0,0 +1,108 @@
+package promcircuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+	"github.com/christopherdavenport/gocircuit/memory"
+	"github.com/christopherdavenport/gocircuit/noop"
+	"github.com/christopherdavenport/gocircuit/timeout"
+)
+
+// metrics waits for the metrics of the named breaker to satisfy done, which is called with the collector locked.
+func metrics(t *testing.T, collector *Collector, name string, done func(b *breakerMetrics) bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		collector.mu.Lock()
+		ok := done(collector.breaker(name))
+		collector.mu.Unlock()
+		if ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("metrics of %s not recorded", name)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Run with -race: the abandoned action keeps running after Protect has returned.
+func TestInstrumentTimeout(t *testing.T) {
+	for name, circuit := range map[string]gocircuit.CircuitBreaker[int]{
+		"events":   memory.NewMemoryCircuitBreaker[int]("events", memory.Settings{Timeout: 10 * time.Millisecond}),
+		"observed": timeout.New[int](nil, 10*time.Millisecond), // Publishes no events, so each call is observed.
+	} {
+		collector := NewCollector()
+		breaker := Instrument(collector, name, circuit)
+
+		done := make(chan struct{})
+		_, err := breaker.Protect(context.Background(), func() (int, error) {
+			defer close(done)
+			time.Sleep(50 * time.Millisecond)
+			return 0, nil
+		})
+		var timeoutErr *gocircuit.TimeoutError
+		if !errors.As(err, &timeoutErr) {
+			t.Fatalf("%s: expected a timeout, got %v", name, err)
+		}
+		<-done
+
+		metrics(t, collector, name, func(b *breakerMetrics) bool { return b.requests == 1 })
+		collector.mu.Lock()
+		b := collector.breakers[name]
+		if b.failures != 1 || b.rejections != 0 {
+			t.Errorf("%s: expected 1 failure and no rejections, got %d and %d", name, b.failures, b.rejections)
+		}
+		if b.latencyCount != 1 || b.latencySum < (10*time.Millisecond).Seconds() {
+			t.Errorf("%s: expected 1 latency of at least the timeout, got %d totalling %vs", name, b.latencyCount, b.latencySum)
+		}
+		collector.mu.Unlock()
+	}
+}
+
+func TestInstrumentCountsAsBreakerDoes(t *testing.T) {
+	collector := NewCollector()
+	circuit := memory.NewMemoryCircuitBreaker[int]("test", memory.Settings{
+		IsSuccessful: func(err error) bool { return err.Error() == "not found" },
+		ReadyToTrip:  func(counts gocircuit.Counts) bool { return counts.TotalFailures > 0 },
+	})
+	breaker := Instrument(collector, "test", circuit)
+	Instrument(collector, "test", circuit) // Records the events once.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _ = breaker.Protect(context.Background(), func() (int, error) { return 0, errors.New("not found") })
+	_, _ = breaker.Protect(ctx, func() (int, error) { return 0, ctx.Err() })
+	_, _ = breaker.Protect(context.Background(), func() (int, error) { return 0, errors.New("failed") })
+	_, _ = breaker.Protect(context.Background(), func() (int, error) { return 1, nil }) // Trips.
+
+	metrics(t, collector, "test", func(b *breakerMetrics) bool { return b.requests == 4 && b.state == gocircuit.StateOpen })
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	b := collector.breakers["test"]
+	if b.successes != 1 || b.ignored != 1 || b.failures != 1 || b.rejections != 1 {
+		t.Errorf("expected 1 success, ignored, failure and rejection, got %d, %d, %d and %d", b.successes, b.ignored, b.failures, b.rejections)
+	}
+	if n := b.transitions[transition{from: gocircuit.StateClosed, to: gocircuit.StateOpen}]; n != 1 {
+		t.Errorf("expected 1 transition to open, got %d", n)
+	}
+}
+
+func TestInstrumentNoopZeroValue(t *testing.T) {
+	collector := NewCollector()
+	breaker := Instrument[int](collector, "noop", noop.NoopCircuitBreaker[int]{}) // Publishes no events.
+	for i := 0; i < 3; i++ {
+		_, _ = breaker.Protect(context.Background(), func() (int, error) { return 0, errors.New("failed") })
+	}
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	b := collector.breakers["noop"]
+	if b.requests != 3 || b.failures != 3 {
+		t.Errorf("expected 3 requests and failures, got %d and %d", b.requests, b.failures)
+	}
+}
@@ -0,0 +1,108 @@
+// Package adaptive throttles requests on the client side like the adaptive package, counting the requests
+// and accepts of every process sharing a Redis server.
+//
+// Requests and accepts are kept in the Requests and Successes sorted sets of realtime.KeysFor, so the key of
+// a throttle must not also be used by a realtime circuit breaker with the same Prefix.
+package adaptive
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+	"github.com/christopherdavenport/gocircuit/adaptive"
+	"github.com/christopherdavenport/gocircuit/redis/realtime"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Settings configures a throttle created by NewRedisAdaptiveThrottle. Buckets is unused, the window slides continuously.
+type Settings struct {
+	adaptive.Settings
+	Prefix string
+
+	// OnStoreError is called when the outcome of an action cannot be recorded.
+	OnStoreError func(operation string, err error)
+}
+
+type redisThrottle[A any] struct {
+	client   *redis.Client
+	key      string
+	settings Settings
+	keys     realtime.Keys
+	events   *gocircuit.EventBus
+}
+
+// NewRedisAdaptiveThrottle creates a throttle named key, sharing its counts with every other throttle of the same key and Prefix.
+// It rejects requests with adaptive.Throttled.
+func NewRedisAdaptiveThrottle[A any](client *redis.Client, key string, settings Settings) gocircuit.CircuitBreaker[A] {
+	settings.Settings = settings.Settings.WithDefaults()
+	return &redisThrottle[A]{
+		client:   client,
+		key:      key,
+		settings: settings,
+		keys:     realtime.KeysFor(settings.Prefix, key),
+		events:   &gocircuit.EventBus{},
+	}
+}
+
+func (t *redisThrottle[A]) Protect(ctx context.Context, action func() (A, error)) (A, error) {
+	probability, err := t.Probability(ctx)
+	if err != nil {
+		var empty A
+		return empty, err
+	}
+	if t.settings.Rand() < probability {
+		t.storeFailed("record rejection", t.record(ctx, gocircuit.OutcomeFailure))
+		t.events.Publish(gocircuit.Event{Kind: gocircuit.EventRejected, Policy: gocircuit.PolicyAdaptive, Breaker: t.key, Err: adaptive.Throttled})
+		var empty A
+		return empty, adaptive.Throttled
+	}
+
+	value, err, outcome := adaptive.Run(ctx, t.key, t.settings.Settings, t.events, action)
+	t.storeFailed("record "+outcome.String(), t.record(context.WithoutCancel(ctx), outcome))
+	return value, err
+}
+
+// Probability returns the current probability of rejecting a request, dropping counts older than the window.
+func (t *redisThrottle[A]) Probability(ctx context.Context) (float64, error) {
+	windowStart := fmt.Sprint(time.Now().Add(-t.settings.Window).UnixNano())
+	pipe := t.client.Pipeline()
+	pipe.ZRemRangeByScore(ctx, t.keys.Requests, "-inf", windowStart)
+	pipe.ZRemRangeByScore(ctx, t.keys.Successes, "-inf", windowStart)
+	requests := pipe.ZCard(ctx, t.keys.Requests)
+	accepts := pipe.ZCard(ctx, t.keys.Successes)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return adaptive.RejectionProbability(requests.Val(), accepts.Val(), t.settings.K), nil
+}
+
+func (t *redisThrottle[A]) record(ctx context.Context, outcome gocircuit.Outcome) error {
+	if outcome == gocircuit.OutcomeIgnore {
+		return nil
+	}
+	now := time.Now()
+	member := redis.Z{Member: uuid.New().String(), Score: float64(now.UnixNano())}
+	pipe := t.client.TxPipeline()
+	pipe.ZAdd(ctx, t.keys.Requests, member)
+	pipe.PExpire(ctx, t.keys.Requests, t.settings.Window)
+	if outcome == gocircuit.OutcomeSuccess {
+		pipe.ZAdd(ctx, t.keys.Successes, member)
+		pipe.PExpire(ctx, t.keys.Successes, t.settings.Window)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (t *redisThrottle[A]) storeFailed(operation string, err error) {
+	if err != nil && t.settings.OnStoreError != nil {
+		t.settings.OnStoreError(operation, err)
+	}
+}
+
+// Subscribe returns a subscription to the activity of this process, buffering up to buffer events.
+func (t *redisThrottle[A]) Subscribe(buffer int) *gocircuit.Subscription {
+	return t.events.Subscribe(buffer)
+}
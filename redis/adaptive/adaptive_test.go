@@ -0,0 +1,114 @@
+package adaptive
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/christopherdavenport/gocircuit"
+	"github.com/christopherdavenport/gocircuit/adaptive"
+	"github.com/christopherdavenport/gocircuit/redis/realtime"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestClient(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return server, client
+}
+
+func testSettings() Settings {
+	return Settings{
+		Settings: adaptive.Settings{Rand: func() float64 { return 0.5 }},
+		Prefix:   "test",
+	}
+}
+
+func TestSharesCountsAcrossThrottles(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	failed := errors.New("failed")
+	first := NewRedisAdaptiveThrottle[int](client, "shared", testSettings())
+	second := NewRedisAdaptiveThrottle[int](client, "shared", testSettings())
+	for i := 0; i < 2; i++ { // Rejection probabilities 0 and 1/2, which 0.5 does not fall below.
+		if _, err := first.Protect(ctx, func() (int, error) { return 0, failed }); err != failed {
+			t.Fatalf("call %d: expected the failure, got %v", i, err)
+		}
+	}
+
+	_, err := second.Protect(ctx, func() (int, error) { return 1, nil }) // Rejected with probability 2/3.
+	if err != adaptive.Throttled || !errors.Is(err, gocircuit.ErrOpen) {
+		t.Errorf("expected the other throttle to be throttled, got %v", err)
+	}
+	keys := realtime.KeysFor("test", "shared")
+	if requests, accepts := client.ZCard(ctx, keys.Requests).Val(), client.ZCard(ctx, keys.Successes).Val(); requests != 3 || accepts != 0 {
+		t.Errorf("expected 3 requests, the rejection included, and no accepts, got %d and %d", requests, accepts)
+	}
+}
+
+func TestAcceptsAndIgnored(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	th := NewRedisAdaptiveThrottle[int](client, "accepts", testSettings())
+	for i := 0; i < 3; i++ {
+		_, _ = th.Protect(context.Background(), func() (int, error) { return 1, nil })
+		_, _ = th.Protect(ctx, func() (int, error) { return 0, ctx.Err() })
+	}
+
+	keys := realtime.KeysFor("test", "accepts")
+	if requests, accepts := client.ZCard(context.Background(), keys.Requests).Val(), client.ZCard(context.Background(), keys.Successes).Val(); requests != 3 || accepts != 3 {
+		t.Errorf("expected 3 requests and accepts, the canceled ignored, got %d and %d", requests, accepts)
+	}
+}
+
+func TestCountsExpireWithWindow(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	settings := testSettings()
+	settings.Window = 20 * time.Millisecond
+	th := NewRedisAdaptiveThrottle[int](client, "window", settings)
+	for i := 0; i < 2; i++ {
+		_, _ = th.Protect(ctx, func() (int, error) { return 0, errors.New("failed") })
+	}
+	time.Sleep(2 * settings.Window)
+
+	probability, err := th.(interface {
+		Probability(ctx context.Context) (float64, error)
+	}).Probability(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if probability != 0 {
+		t.Errorf("expected the failures to leave the window, got a rejection probability of %g", probability)
+	}
+}
+
+func TestStoreErrors(t *testing.T) {
+	server, client := newTestClient(t)
+	settings := testSettings()
+	var operations []string
+	settings.OnStoreError = func(operation string, err error) { operations = append(operations, operation) }
+	th := NewRedisAdaptiveThrottle[int](client, "store", settings)
+
+	_, err := th.Protect(context.Background(), func() (int, error) {
+		server.Close() // Redis goes away while the action runs, so its outcome cannot be recorded.
+		return 1, nil
+	})
+	if err != nil {
+		t.Errorf("expected the result of the action, got %v", err)
+	}
+	if len(operations) != 1 || operations[0] != "record success" {
+		t.Errorf("expected the success to be reported lost, got %v", operations)
+	}
+
+	calls := 0
+	_, err = th.Protect(context.Background(), func() (int, error) { calls++; return 1, nil })
+	if err == nil || calls != 0 {
+		t.Errorf("expected the unreadable counts to fail the request before the action, got %v after %d calls", err, calls)
+	}
+}
@@ -0,0 +1,175 @@
+// Package bulkhead limits the number of actions in flight at once across every process sharing a Redis server.
+//
+// Each running action holds a lease in a sorted set scored by its expiry, by the clock of the Redis server, so the
+// slots of a process that dies mid-action are reclaimed once its leases expire. Leases are renewed while their
+// action runs.
+package bulkhead
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+	"github.com/christopherdavenport/gocircuit/bulkhead"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Settings configures a bulkhead created by NewRedisBulkhead.
+type Settings struct {
+	Prefix        string
+	MaxConcurrent int64         // The number of actions allowed in flight at once across all processes. Must be positive.
+	Lease         time.Duration // How long a slot is held without renewal. Defaults to 30 seconds.
+
+	MaxWait      time.Duration // How long a caller waits for a slot, bounded by its context. Zero rejects immediately when full.
+	PollInterval time.Duration // How often a waiting caller retries. Defaults to 50 milliseconds.
+
+	// OnStoreError is called when a lease cannot be renewed or released, in which case it expires on its own,
+	// and with LeaseLost when a lease has expired and may have been reclaimed while its action still runs.
+	OnStoreError func(operation string, err error)
+}
+
+// LeaseLost is reported to OnStoreError when the lease of a running action has expired, for example after the
+// process was paused for longer than Lease, so the bulkhead may be admitting more than MaxConcurrent actions.
+// The lease is not renewed again.
+const LeaseLost bulkhead.BulkheadError = "bulkhead lease expired while its action was running"
+
+type redisBulkhead[A any] struct {
+	client   *redis.Client
+	key      string
+	settings Settings
+	events   *gocircuit.EventBus
+}
+
+// NewRedisBulkhead creates a bulkhead named key, sharing its slots with every other bulkhead of the same key and Prefix.
+func NewRedisBulkhead[A any](client *redis.Client, key string, settings Settings) gocircuit.CircuitBreaker[A] {
+	if settings.MaxConcurrent <= 0 {
+		panic("bulkhead: MaxConcurrent must be positive")
+	}
+	if settings.Lease <= 0 {
+		settings.Lease = 30 * time.Second
+	}
+	if settings.PollInterval <= 0 {
+		settings.PollInterval = 50 * time.Millisecond
+	}
+	return &redisBulkhead[A]{client: client, key: key, settings: settings, events: &gocircuit.EventBus{}}
+}
+
+func semaphoreKey(prefix string, key string) string {
+	return fmt.Sprintf("%s:bulkhead:%s", prefix, key)
+}
+
+// nowScript sets now to the time of the Redis server in milliseconds.
+const nowScript = `
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+`
+
+// acquireScript drops expired leases and adds a lease for ARGV[2], expiring in ARGV[3] milliseconds, if fewer
+// than ARGV[1] remain.
+var acquireScript = redis.NewScript(nowScript + `
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+if redis.call('ZCARD', KEYS[1]) < tonumber(ARGV[1]) then
+	redis.call('ZADD', KEYS[1], now + tonumber(ARGV[3]), ARGV[2])
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+	return 1
+end
+return 0
+`)
+
+// renewScript extends the lease ARGV[1] to expire in ARGV[2] milliseconds, unless it has already expired, in
+// which case it is removed. It returns whether the lease was renewed.
+var renewScript = redis.NewScript(nowScript + `
+local expiry = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if not expiry or tonumber(expiry) < now then
+	redis.call('ZREM', KEYS[1], ARGV[1])
+	return 0
+end
+redis.call('ZADD', KEYS[1], now + tonumber(ARGV[2]), ARGV[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return 1
+`)
+
+// inFlightScript counts the unexpired leases.
+var inFlightScript = redis.NewScript(nowScript + `
+return redis.call('ZCOUNT', KEYS[1], now, '+inf')
+`)
+
+func (b *redisBulkhead[A]) Protect(ctx context.Context, action func() (A, error)) (A, error) {
+	lease := uuid.New().String()
+	if err := b.acquire(ctx, lease); err != nil {
+		b.events.Publish(gocircuit.Event{Kind: gocircuit.EventRejected, Policy: gocircuit.PolicyBulkhead, Breaker: b.key, Err: err})
+		var empty A
+		return empty, err
+	}
+	done := make(chan struct{})
+	go b.renew(ctx, lease, done)
+	defer func() {
+		close(done)
+		// The action has run, so release the lease even if the caller's context is done.
+		err := b.client.ZRem(context.WithoutCancel(ctx), semaphoreKey(b.settings.Prefix, b.key), lease).Err()
+		b.storeFailed("release", err)
+	}()
+	return bulkhead.Run(ctx, b.key, b.events, action)
+}
+
+func (b *redisBulkhead[A]) acquire(ctx context.Context, lease string) error {
+	deadline := time.Now().Add(b.settings.MaxWait)
+	for {
+		now := time.Now()
+		acquired, err := acquireScript.Run(ctx, b.client, []string{semaphoreKey(b.settings.Prefix, b.key)},
+			b.settings.MaxConcurrent, lease, b.settings.Lease.Milliseconds()).Int()
+		if err != nil {
+			return err
+		}
+		if acquired == 1 {
+			return nil
+		}
+		if !now.Add(b.settings.PollInterval).Before(deadline) {
+			return bulkhead.BulkheadFull
+		}
+		select {
+		case <-time.After(b.settings.PollInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// renew extends lease until done is closed, or until it finds the lease has expired.
+func (b *redisBulkhead[A]) renew(ctx context.Context, lease string, done chan struct{}) {
+	ctx = context.WithoutCancel(ctx) // The lease must outlive the caller's context for as long as the action runs.
+	ticker := time.NewTicker(b.settings.Lease / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			renewed, err := renewScript.Run(ctx, b.client, []string{semaphoreKey(b.settings.Prefix, b.key)},
+				lease, b.settings.Lease.Milliseconds()).Int()
+			if err == nil && renewed == 0 {
+				b.storeFailed("renew", LeaseLost)
+				return
+			}
+			b.storeFailed("renew", err)
+		}
+	}
+}
+
+func (b *redisBulkhead[A]) storeFailed(operation string, err error) {
+	if err != nil && b.settings.OnStoreError != nil {
+		b.settings.OnStoreError(operation, err)
+	}
+}
+
+// InFlight returns the number of unexpired leases held across all processes.
+func (b *redisBulkhead[A]) InFlight(ctx context.Context) (int64, error) {
+	return inFlightScript.Run(ctx, b.client, []string{semaphoreKey(b.settings.Prefix, b.key)}).Int64()
+}
+
+// Subscribe returns a subscription to the activity of this process, buffering up to buffer events.
+func (b *redisBulkhead[A]) Subscribe(buffer int) *gocircuit.Subscription {
+	return b.events.Subscribe(buffer)
+}
@@ -0,0 +1,123 @@
+package bulkhead
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/christopherdavenport/gocircuit"
+	"github.com/christopherdavenport/gocircuit/bulkhead"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestClient(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return server, client
+}
+
+// hold runs an action in b until release is closed, returning once it is running.
+func hold(b gocircuit.CircuitBreaker[int], release chan struct{}) {
+	running := make(chan struct{})
+	go func() {
+		_, _ = b.Protect(context.Background(), func() (int, error) {
+			close(running)
+			<-release
+			return 0, nil
+		})
+	}()
+	<-running
+}
+
+func free(b gocircuit.CircuitBreaker[int]) error {
+	_, err := b.Protect(context.Background(), func() (int, error) { return 0, nil })
+	return err
+}
+
+func TestSharedLimit(t *testing.T) {
+	_, client := newTestClient(t)
+	settings := Settings{Prefix: "test", MaxConcurrent: 1}
+	a := NewRedisBulkhead[int](client, "limit", settings)
+	b := NewRedisBulkhead[int](client, "limit", settings) // Another process.
+	release := make(chan struct{})
+	hold(a, release)
+
+	if err := free(b); err != bulkhead.BulkheadFull {
+		t.Errorf("expected BulkheadFull, got %v", err)
+	}
+	if n, err := b.(*redisBulkhead[int]).InFlight(context.Background()); err != nil || n != 1 {
+		t.Errorf("expected 1 lease in flight, got %d, %v", n, err)
+	}
+	close(release)
+	time.Sleep(10 * time.Millisecond)
+	if err := free(b); err != nil {
+		t.Errorf("expected the released slot to be free, got %v", err)
+	}
+}
+
+func TestWaitsUntilContextDone(t *testing.T) {
+	_, client := newTestClient(t)
+	b := NewRedisBulkhead[int](client, "wait", Settings{Prefix: "test", MaxConcurrent: 1, MaxWait: time.Second, PollInterval: time.Millisecond})
+	release := make(chan struct{})
+	hold(b, release)
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := b.Protect(ctx, func() (int, error) { return 0, nil }); err != context.DeadlineExceeded {
+		t.Errorf("expected the wait to end with the context, got %v", err)
+	}
+}
+
+func TestRenewalKeepsSlot(t *testing.T) {
+	_, client := newTestClient(t)
+	settings := Settings{Prefix: "test", MaxConcurrent: 1, Lease: 60 * time.Millisecond}
+	a := NewRedisBulkhead[int](client, "renew", settings)
+	b := NewRedisBulkhead[int](client, "renew", settings)
+	release := make(chan struct{})
+	hold(a, release)
+	defer close(release)
+
+	time.Sleep(3 * settings.Lease) // Expired but for renewal.
+	if err := free(b); err != bulkhead.BulkheadFull {
+		t.Errorf("expected the renewed lease to keep its slot, got %v", err)
+	}
+}
+
+func TestExpiredLeaseReapedAndReported(t *testing.T) {
+	server, client := newTestClient(t)
+	start := time.Now()
+	server.SetTime(start)
+	var lost atomic.Int64
+	settings := Settings{Prefix: "test", MaxConcurrent: 1, Lease: 90 * time.Millisecond}
+	settings.OnStoreError = func(operation string, err error) {
+		if err == LeaseLost {
+			lost.Add(1)
+		}
+	}
+	a := NewRedisBulkhead[int](client, "reap", settings)
+	b := NewRedisBulkhead[int](client, "reap", Settings{Prefix: "test", MaxConcurrent: 1, Lease: time.Minute})
+	release := make(chan struct{})
+	hold(a, release)
+	defer close(release)
+
+	server.SetTime(start.Add(time.Second)) // By the server's clock, a has not renewed its lease in time.
+	if err := free(b); err != nil {
+		t.Fatalf("expected the expired lease to be reaped, got %v", err)
+	}
+	hold(b, release) // Takes the reclaimed slot while a still runs.
+	deadline := time.Now().Add(time.Second)
+	for lost.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the lost lease to be reported")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n, _ := b.(*redisBulkhead[int]).InFlight(context.Background()); n != 1 {
+		t.Errorf("expected only the new lease in flight, got %d", n)
+	}
+}
@@ -0,0 +1,116 @@
+// Package ratelimit limits the rate of requests across every process sharing a Redis server, with a token bucket
+// updated atomically by a Lua script.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+	"github.com/redis/go-redis/v9"
+)
+
+// LimitError rejects a request made while the bucket is empty.
+type LimitError struct {
+	Key  string
+	Wait time.Duration // How long until the bucket holds enough tokens.
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded for %s, retry after %s", e.Key, e.Wait)
+}
+
+// RetryAfter returns Wait, see gocircuit.RetryAfter.
+func (e *LimitError) RetryAfter() time.Duration {
+	return e.Wait
+}
+
+// Settings configures a limiter created by NewRedisRateLimiter.
+type Settings struct {
+	Prefix string
+	Rate   float64 // The number of tokens added to the bucket per second. Must be positive.
+	Burst  int64   // The most tokens the bucket holds. Defaults to one second of Rate, and at least 1.
+	Cost   int64   // The number of tokens taken by each request. Defaults to 1, and must not exceed Burst.
+}
+
+type limiter[A any] struct {
+	client   *redis.Client
+	key      string
+	settings Settings
+	events   *gocircuit.EventBus
+}
+
+// NewRedisRateLimiter creates a limiter named key, sharing its bucket with every other limiter of the same key and Prefix.
+// It rejects requests with a *LimitError, and panics if Rate is not positive or Cost exceeds Burst, as no request could ever be admitted.
+func NewRedisRateLimiter[A any](client *redis.Client, key string, settings Settings) gocircuit.CircuitBreaker[A] {
+	if settings.Rate <= 0 {
+		panic("ratelimit: Rate must be positive")
+	}
+	if settings.Burst <= 0 {
+		settings.Burst = max(1, int64(settings.Rate))
+	}
+	if settings.Cost <= 0 {
+		settings.Cost = 1
+	}
+	if settings.Cost > settings.Burst {
+		panic("ratelimit: Cost must not exceed Burst")
+	}
+	return &limiter[A]{client: client, key: key, settings: settings, events: &gocircuit.EventBus{}}
+}
+
+func bucketKey(prefix string, key string) string {
+	return fmt.Sprintf("%s:ratelimit:%s", prefix, key)
+}
+
+// takeScript refills the bucket for the milliseconds since it was last updated, by the clock of the Redis server
+// so that every process refills it alike, then takes ARGV[3] tokens if it holds that many. It returns whether
+// they were taken, and otherwise the milliseconds until they can be.
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'time')
+local tokens = tonumber(bucket[1]) or burst
+local last = tonumber(bucket[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - last) * rate)
+local taken, wait = 0, 0
+if tokens >= cost then
+	tokens = tokens - cost
+	taken = 1
+else
+	wait = math.ceil((cost - tokens) / rate)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'time', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate) + 1)
+return {taken, wait}
+`)
+
+func (l *limiter[A]) Protect(ctx context.Context, action func() (A, error)) (A, error) {
+	if err := l.take(ctx); err != nil {
+		l.events.Publish(gocircuit.Event{Kind: gocircuit.EventRejected, Policy: gocircuit.PolicyRateLimit, Breaker: l.key, Err: err})
+		var empty A
+		return empty, err
+	}
+	l.events.Publish(gocircuit.Event{Kind: gocircuit.EventAdmitted, Policy: gocircuit.PolicyRateLimit, Breaker: l.key})
+	return action()
+}
+
+func (l *limiter[A]) take(ctx context.Context) error {
+	result, err := takeScript.Run(ctx, l.client, []string{bucketKey(l.settings.Prefix, l.key)},
+		l.settings.Rate/1000, l.settings.Burst, l.settings.Cost).Int64Slice()
+	if err != nil {
+		return err
+	}
+	if result[0] == 1 {
+		return nil
+	}
+	return &LimitError{Key: l.key, Wait: time.Duration(result[1]) * time.Millisecond}
+}
+
+// Subscribe returns a subscription to the admissions and rejections of this process, buffering up to buffer events.
+func (l *limiter[A]) Subscribe(buffer int) *gocircuit.Subscription {
+	return l.events.Subscribe(buffer)
+}
@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/christopherdavenport/gocircuit"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestLimiter returns a limiter of a fresh miniredis server, whose clock is set to start.
+func newTestLimiter(t *testing.T, settings Settings, start time.Time) (*miniredis.Miniredis, gocircuit.CircuitBreaker[int]) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	server.SetTime(start)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	settings.Prefix = "test"
+	return server, NewRedisRateLimiter[int](client, "limit", settings)
+}
+
+func take(limiter gocircuit.CircuitBreaker[int]) error {
+	_, err := limiter.Protect(context.Background(), func() (int, error) { return 0, nil })
+	return err
+}
+
+func TestBurstThenRetryAfter(t *testing.T) {
+	_, limiter := newTestLimiter(t, Settings{Rate: 1, Burst: 2}, time.Now())
+	for i := 0; i < 2; i++ {
+		if err := take(limiter); err != nil {
+			t.Fatalf("request %d: expected the burst to be admitted, got %v", i, err)
+		}
+	}
+	err := take(limiter)
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("expected a *LimitError once the burst is spent, got %v", err)
+	}
+	if wait, ok := gocircuit.RetryAfter(err); !ok || wait <= 0 || wait > time.Second {
+		t.Errorf("expected to retry after at most a second, got %s", wait)
+	}
+}
+
+func TestRefillsByServerClock(t *testing.T) {
+	start := time.Now()
+	server, limiter := newTestLimiter(t, Settings{Rate: 1, Burst: 1}, start)
+	if err := take(limiter); err != nil {
+		t.Fatal(err)
+	}
+	if err := take(limiter); err == nil {
+		t.Fatal("expected the empty bucket to reject")
+	}
+	server.SetTime(start.Add(time.Second)) // Only the server's clock moves.
+	if err := take(limiter); err != nil {
+		t.Errorf("expected the bucket to refill by the server's clock, got %v", err)
+	}
+}
+
+func TestCostTakesSeveralTokens(t *testing.T) {
+	_, limiter := newTestLimiter(t, Settings{Rate: 1, Burst: 3, Cost: 2}, time.Now())
+	if err := take(limiter); err != nil {
+		t.Fatal(err)
+	}
+	if err := take(limiter); err == nil {
+		t.Error("expected a second request costing 2 of the 1 token left to be rejected")
+	}
+}
+
+func TestCostExceedingBurstPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic for a Cost exceeding Burst")
+		}
+	}()
+	NewRedisRateLimiter[int](nil, "test", Settings{Rate: 1, Burst: 2, Cost: 3})
+}
+
+func TestDefaultBurstCoversCost(t *testing.T) {
+	NewRedisRateLimiter[int](nil, "test", Settings{Rate: 0.5})
+}
@@ -0,0 +1,135 @@
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+	"github.com/redis/go-redis/v9"
+)
+
+// Reasons recorded alongside a Transition.
+const (
+	ReasonReadyToTrip       = gocircuit.ReasonReadyToTrip
+	ReasonOpenTimeout       = gocircuit.ReasonOpenTimeout
+	ReasonProbeFailed       = gocircuit.ReasonProbeFailed
+	ReasonProbeSucceeded    = gocircuit.ReasonProbeSucceeded
+	ReasonProbeIgnored      = gocircuit.ReasonProbeIgnored
+	ReasonHealthCheckPassed = gocircuit.ReasonHealthCheckPassed
+)
+
+// Transition is a single state change read back from the audit stream.
+// Its Id is the stream entry id and its Instance the InstanceId of the process that made it.
+type Transition = gocircuit.Transition
+
+func auditKey(prefix string, key string) string {
+	return fmt.Sprintf("%s:audit:%s", prefix, key)
+}
+
+func defaultInstanceId() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s:%d", hostname, os.Getpid())
+}
+
+// recordTransition appends the transition to the audit stream as part of pipe.
+// It does nothing if auditing is disabled.
+func recordTransition(pipe redis.Pipeliner, ctx context.Context, key string, settings CircuitBreakerSettings, from gocircuit.State, to gocircuit.State, counts Counts, reason string, systime time.Time) {
+	if settings.AuditMaxLen <= 0 {
+		return
+	}
+	pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: auditKey(settings.Prefix, key),
+		MaxLen: settings.AuditMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			"from":                 from.String(),
+			"to":                   to.String(),
+			"reason":               reason,
+			"instance":             settings.InstanceId,
+			"time":                 systime.UnixNano(),
+			"requests":             counts.Requests,
+			"successes":            counts.TotalSuccesses,
+			"failures":             counts.TotalFailures,
+			"consecutiveSuccesses": counts.ConsecutiveSuccesses,
+			"consecutiveFailures":  counts.ConsecutiveFailures,
+		},
+	})
+}
+
+// ReadTransitions returns up to count of the most recent transitions of the
+// circuit breaker stored under key, newest first.
+func ReadTransitions(client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings, count int64) ([]Transition, error) {
+	messages, err := client.XRevRangeN(ctx, auditKey(settings.Prefix, key), "+", "-", count).Result()
+	if err != nil {
+		return nil, err
+	}
+	transitions := make([]Transition, 0, len(messages))
+	for _, message := range messages {
+		transition, err := transitionFromMessage(message)
+		if err != nil {
+			return nil, err
+		}
+		transitions = append(transitions, transition)
+	}
+	return transitions, nil
+}
+
+func transitionFromMessage(message redis.XMessage) (Transition, error) {
+	field := func(name string) string {
+		value, _ := message.Values[name].(string)
+		return value
+	}
+	integer := func(name string) (int64, error) {
+		i, err := strconv.ParseInt(field(name), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s in transition %s: %q", name, message.ID, field(name))
+		}
+		return i, nil
+	}
+
+	from, err := gocircuit.StateFromString(field("from"))
+	if err != nil {
+		return Transition{}, err
+	}
+	to, err := gocircuit.StateFromString(field("to"))
+	if err != nil {
+		return Transition{}, err
+	}
+	systime, err := timeFromString(field("time"))
+	if err != nil {
+		return Transition{}, err
+	}
+
+	var counts Counts
+	for _, c := range []struct {
+		name string
+		dest *int64
+	}{
+		{"requests", &counts.Requests},
+		{"successes", &counts.TotalSuccesses},
+		{"failures", &counts.TotalFailures},
+		{"consecutiveSuccesses", &counts.ConsecutiveSuccesses},
+		{"consecutiveFailures", &counts.ConsecutiveFailures},
+	} {
+		*c.dest, err = integer(c.name)
+		if err != nil {
+			return Transition{}, err
+		}
+	}
+
+	return Transition{
+		Id:       message.ID,
+		From:     from,
+		To:       to,
+		Counts:   counts,
+		Reason:   field("reason"),
+		Instance: field("instance"),
+		Time:     systime,
+	}, nil
+}
@@ -0,0 +1,87 @@
+package realtime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+// cycle trips cb, then waits out the open timeout for a successful probe to close it again.
+func cycle(t *testing.T, cb gocircuit.CircuitBreaker[int], openTimeout time.Duration) {
+	t.Helper()
+	trip(cb)
+	time.Sleep(2 * openTimeout)
+	if _, err := cb.Protect(context.Background(), func() (int, error) { return 1, nil }); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadTransitions(t *testing.T) {
+	_, client := newTestClient(t)
+	settings := testSettings()
+	settings.OpenTimeout = 10 * time.Millisecond
+	settings.AuditMaxLen = 10
+	cb := NewRealtimeRedisCircuitBreaker[int](client, "audit", settings)
+	cycle(t, cb, settings.OpenTimeout)
+
+	transitions, err := ReadTransitions(client, context.Background(), "audit", settings, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		from, to gocircuit.State
+		reason   string
+	}{
+		{gocircuit.StateHalfOpen, gocircuit.StateClosed, ReasonProbeSucceeded},
+		{gocircuit.StateOpen, gocircuit.StateHalfOpen, ReasonOpenTimeout},
+		{gocircuit.StateClosed, gocircuit.StateOpen, ReasonReadyToTrip},
+	}
+	if len(transitions) != len(expected) {
+		t.Fatalf("expected %d transitions, got %+v", len(expected), transitions)
+	}
+	for i, e := range expected {
+		transition := transitions[i]
+		if transition.From != e.from || transition.To != e.to || transition.Reason != e.reason || transition.Instance != "test" {
+			t.Errorf("expected transition %d from %s to %s for %s by test, got %+v", i, e.from, e.to, e.reason, transition)
+		}
+		if i > 0 && transition.Time.After(transitions[i-1].Time) {
+			t.Errorf("expected transitions newest first, got %+v", transitions)
+		}
+	}
+	if failures := transitions[2].Counts.ConsecutiveFailures; failures != 3 {
+		t.Errorf("expected the trip to record 3 consecutive failures, got %d", failures)
+	}
+}
+
+func TestAuditMaxLen(t *testing.T) {
+	_, client := newTestClient(t)
+	settings := testSettings()
+	settings.OpenTimeout = 10 * time.Millisecond
+	settings.AuditMaxLen = 2 // Redis trims approximately, miniredis exactly.
+	cb := NewRealtimeRedisCircuitBreaker[int](client, "audit", settings)
+	cycle(t, cb, settings.OpenTimeout)
+
+	transitions, err := ReadTransitions(client, context.Background(), "audit", settings, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transitions) != 2 || transitions[0].Reason != ReasonProbeSucceeded || transitions[1].Reason != ReasonOpenTimeout {
+		t.Errorf("expected the 2 newest transitions kept, got %+v", transitions)
+	}
+}
+
+func TestAuditDisabled(t *testing.T) {
+	_, client := newTestClient(t)
+	cb := NewRealtimeRedisCircuitBreaker[int](client, "audit", testSettings())
+	trip(cb)
+
+	transitions, err := ReadTransitions(client, context.Background(), "audit", testSettings(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(transitions) != 0 {
+		t.Errorf("expected no transitions without AuditMaxLen, got %+v", transitions)
+	}
+}
@@ -0,0 +1,93 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+// byStatus counts a 503 as a failure and ignores a 404, whatever the error.
+func byStatus(status int, err error) gocircuit.Outcome {
+	switch {
+	case status == 503 || err != nil:
+		return gocircuit.OutcomeFailure
+	case status == 404:
+		return gocircuit.OutcomeIgnore
+	default:
+		return gocircuit.OutcomeSuccess
+	}
+}
+
+func TestClassifierCountsResults(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	cb := NewRealtimeRedisCircuitBreakerWithClassifier[int](client, "classify", testSettings(), byStatus)
+	for _, status := range []int{200, 503, 404, 404} {
+		if _, err := cb.Protect(ctx, func() (int, error) { return status, nil }); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snapshot, err := cb.(gocircuit.Inspector).Inspect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := snapshot.Counts; c.Requests != 2 || c.TotalSuccesses != 1 || c.TotalFailures != 1 || c.ConsecutiveFailures != 1 {
+		t.Errorf("expected a success and a failure counted, the rest ignored, got %+v", c)
+	}
+}
+
+func TestCallerCancelIgnored(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cb := NewRealtimeRedisCircuitBreaker[int](client, "cancel", testSettings())
+	for i := 0; i < 5; i++ {
+		_, _ = cb.Protect(ctx, func() (int, error) { return 0, ctx.Err() })
+	}
+
+	snapshot, err := cb.(gocircuit.Inspector).Inspect(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.State != gocircuit.StateClosed || snapshot.Counts.Requests != 0 {
+		t.Errorf("expected canceled requests to count as nothing, got %s with %+v", snapshot.State, snapshot.Counts)
+	}
+}
+
+func TestIgnoredProbeLetsNextCallerProbe(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	settings := testSettings()
+	settings.OpenTimeout = 10 * time.Millisecond
+	settings.AuditMaxLen = 10
+	cb := NewRealtimeRedisCircuitBreakerWithClassifier[int](client, "probe", settings, byStatus)
+	for i := 0; i < 4; i++ { // Trips.
+		_, _ = cb.Protect(ctx, func() (int, error) { return 0, errors.New("failed") })
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	if _, err := cb.Protect(ctx, func() (int, error) { return 404, nil }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cb.Protect(ctx, func() (int, error) { return 200, nil }); err != nil {
+		t.Fatalf("expected the next caller to probe at once, got %v", err)
+	}
+
+	transitions, err := ReadTransitions(client, ctx, "probe", settings, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reasons []string
+	for _, transition := range transitions {
+		reasons = append([]string{transition.Reason}, reasons...)
+	}
+	expected := []string{ReasonReadyToTrip, ReasonOpenTimeout, ReasonProbeIgnored, ReasonOpenTimeout, ReasonProbeSucceeded}
+	if fmt.Sprint(reasons) != fmt.Sprint(expected) {
+		t.Errorf("expected the ignored probe to reopen the breaker for the next, got %q", reasons)
+	}
+}
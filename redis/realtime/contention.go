@@ -0,0 +1,48 @@
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// ContentionError is returned when other instances kept changing the state of the circuit breaker
+// before this one could, for more attempts than MaxContentionRetries allows. The action has not run.
+type ContentionError struct {
+	Key      string
+	Attempts int
+}
+
+func (e *ContentionError) Error() string {
+	return fmt.Sprintf("circuit breaker %s gave up after %d attempts to change contended state", e.Key, e.Attempts)
+}
+
+func contentionRetries(settings CircuitBreakerSettings) int {
+	if settings.MaxContentionRetries == 0 {
+		return 10
+	}
+	return max(0, settings.MaxContentionRetries)
+}
+
+// contentionBackoff returns a random wait of up to ContentionBackoff doubled for each attempt, capped at 64 times.
+func contentionBackoff(settings CircuitBreakerSettings, attempt int) time.Duration {
+	backoff := settings.ContentionBackoff
+	if backoff <= 0 {
+		backoff = time.Millisecond
+	}
+	backoff <<= min(attempt-1, 6)
+	return time.Duration(rand.Int64N(int64(backoff)) + 1)
+}
+
+// sleep waits for d, or returns the error of ctx if it is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
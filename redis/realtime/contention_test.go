@@ -0,0 +1,70 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+func TestContentionGivesUp(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	settings := testSettings()
+	settings.MaxContentionRetries = 2
+	settings.ReadyToTrip = func(counts Counts) bool {
+		// Another instance moves the state on between reading it and tripping, every time.
+		moved, _ := StateStructToString(StateStruct{State: gocircuit.StateClosed, TimeOpen: time.Now()})
+		client.Set(ctx, stateKey(settings.Prefix, "contended"), moved, time.Hour)
+		return true
+	}
+	cb := NewRealtimeRedisCircuitBreaker[int](client, "contended", settings)
+
+	ran := false
+	_, err := cb.Protect(ctx, func() (int, error) {
+		ran = true
+		return 0, nil
+	})
+	var contention *ContentionError
+	if !errors.As(err, &contention) || contention.Attempts != 3 {
+		t.Fatalf("expected a contention error after 3 attempts, got %v", err)
+	}
+	if ran {
+		t.Error("expected the action not to run")
+	}
+	stats := cb.(StatsProvider).Stats()
+	if stats.Contentions != 3 || stats.ContentionFailures != 1 {
+		t.Errorf("expected 3 contentions and 1 failure, got %+v", stats)
+	}
+}
+
+func TestFailedProbeAfterResetStaysClosed(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	settings := testSettings()
+	settings.OpenTimeout = 10 * time.Millisecond
+	cb := NewRealtimeRedisCircuitBreaker[int](client, "probe-reset", settings)
+	for i := 0; i < 4; i++ { // The fourth request trips the circuit breaker.
+		_, _ = cb.Protect(ctx, func() (int, error) { return 0, errors.New("failed") })
+	}
+	time.Sleep(2 * settings.OpenTimeout)
+
+	_, _ = cb.Protect(ctx, func() (int, error) {
+		if err := Reset(client, ctx, "probe-reset", settings); err != nil { // By an operator while the probe runs.
+			t.Error(err)
+		}
+		return 0, errors.New("failed")
+	})
+	snapshot, err := Inspect(client, ctx, "probe-reset", settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.State != gocircuit.StateClosed {
+		t.Errorf("expected the reset to win over the failed probe, got %s", snapshot.State)
+	}
+	if n, _ := client.ZCard(ctx, halfOpenKey(settings.Prefix, "probe-reset")).Result(); n != 0 {
+		t.Errorf("expected the probe to be released, %d remain", n)
+	}
+}
@@ -0,0 +1,86 @@
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/christopherdavenport/gocircuit"
+	"github.com/redis/go-redis/v9"
+)
+
+func outcomesKey(prefix string, key string) string {
+	return fmt.Sprintf("%s:outcomes:%s", prefix, key)
+}
+
+// pushOutcome records an outcome in the capped list of the last WindowSize outcomes as part of pipe.
+// Entries are the outcome member prefixed with "s:" for a success or "f:" for a failure, newest first.
+func pushOutcome(pipe redis.Pipeliner, ctx context.Context, key string, settings CircuitBreakerSettings, success bool, member string) {
+	kind := "f:"
+	if success {
+		kind = "s:"
+	}
+	pipe.LPush(ctx, outcomesKey(settings.Prefix, key), kind+member)
+	pipe.LTrim(ctx, outcomesKey(settings.Prefix, key), 0, int64(settings.WindowSize)-1)
+	pipe.PExpire(ctx, outcomesKey(settings.Prefix, key), settings.RedisKeyTimeout)
+}
+
+// countOutcomesScript returns the requests, successes, failures, consecutive successes and consecutive failures
+// in the list of outcomes, followed by the same sums of their weights, as strings to keep the fractions.
+var countOutcomesScript = redis.NewScript(`
+local counts = {0, 0, 0, 0, 0}
+local weights = {0, 0, 0, 0, 0}
+local run = nil
+for _, entry in ipairs(redis.call('LRANGE', KEYS[1], 0, -1)) do
+	local success = string.sub(entry, 1, 2) == 's:'
+	local weight = string.match(entry, '|([^|]+)$')
+	weight = weight and tonumber(weight) or 1
+	local total, consecutive = 3, 5
+	if success then
+		total, consecutive = 2, 4
+	end
+	counts[1], weights[1] = counts[1] + 1, weights[1] + weight
+	counts[total], weights[total] = counts[total] + 1, weights[total] + weight
+	if run == nil then
+		run = success
+	end
+	if run == success then
+		counts[consecutive], weights[consecutive] = counts[consecutive] + 1, weights[consecutive] + weight
+	else
+		run = 'ended'
+	end
+end
+local result = {}
+for i = 1, 5 do
+	result[i] = tostring(counts[i])
+	result[i + 5] = tostring(weights[i])
+end
+return result
+`)
+
+// countsFromStrings parses the result of countOutcomesScript.
+func countsFromStrings(values []string) (Counts, error) {
+	if len(values) != 10 {
+		return Counts{}, fmt.Errorf("expected 10 outcome counts, got %d", len(values))
+	}
+	var counts [5]int64
+	for i := range counts {
+		count, err := strconv.ParseInt(values[i], 10, 64)
+		if err != nil {
+			return Counts{}, err
+		}
+		counts[i] = count
+	}
+	weighted, err := weightedCountsFromStrings(values[5:])
+	if err != nil {
+		return Counts{}, err
+	}
+	return gocircuit.Counts{
+		Requests:             counts[0],
+		TotalSuccesses:       counts[1],
+		TotalFailures:        counts[2],
+		ConsecutiveSuccesses: counts[3],
+		ConsecutiveFailures:  counts[4],
+		Weighted:             *weighted,
+	}, nil
+}
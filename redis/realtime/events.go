@@ -0,0 +1,41 @@
+package realtime
+
+import (
+	"context"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+func (inst *instance) publish(event gocircuit.Event) {
+	event.Policy, event.Breaker = gocircuit.PolicyCircuitBreaker, inst.key
+	inst.events.Publish(event)
+}
+
+// stateChanged notifies listeners and subscribers of a state change committed to Redis.
+func (inst *instance) stateChanged(from gocircuit.State, to gocircuit.State) {
+	inst.notifier.Notify(from, to)
+	inst.publish(gocircuit.Event{Kind: gocircuit.EventStateChange, From: from, State: to})
+}
+
+func rejected(ctx context.Context, key string, settings CircuitBreakerSettings, inst *instance, state gocircuit.State, err error) {
+	logRejected(ctx, key, settings, state, err)
+	inst.publish(gocircuit.Event{Kind: gocircuit.EventRejected, State: state, Err: err})
+}
+
+// runAction runs an action admitted in state, publishing and returning its outcome.
+func runAction[A any](ctx context.Context, settings CircuitBreakerSettings, inst *instance, classify gocircuit.Classifier[A], state gocircuit.State, action func() (A, error)) (A, error, gocircuit.Outcome) {
+	inst.publish(gocircuit.Event{Kind: gocircuit.EventAdmitted, State: state})
+	start := time.Now()
+	value, err := action()
+	duration := time.Since(start)
+
+	outcome := gocircuit.Classify(ctx, classify, settings.IsSuccessful, value, err)
+	gocircuit.ObserveOutcome(ctx, outcome)
+	kind := gocircuit.EventKindOf(outcome)
+	inst.publish(gocircuit.Event{Kind: kind, State: state, Err: err, Duration: duration})
+	if settings.SlowCallThreshold > 0 && duration > settings.SlowCallThreshold {
+		inst.publish(gocircuit.Event{Kind: gocircuit.EventSlowCall, State: state, Err: err, Duration: duration})
+	}
+	return value, err, outcome
+}
@@ -0,0 +1,97 @@
+// Concurrent records outcomes from many goroutines over many Redis clients at once, and exits with an error
+// unless every one of them is counted.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+	gcredis "github.com/christopherdavenport/gocircuit/redis/realtime"
+	"github.com/redis/go-redis/v9"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:6379", "Redis address")
+	clients := flag.Int("clients", 8, "number of Redis clients, each with its own circuit breaker instance")
+	goroutines := flag.Int("goroutines", 16, "number of goroutines per client")
+	calls := flag.Int("calls", 50, "number of calls per goroutine")
+	flag.Parse()
+
+	key := "concurrent"
+	ctx := context.Background()
+	settings := gcredis.CircuitBreakerSettings{
+		Prefix:          "circuitBreaker",
+		RedisKeyTimeout: 5 * time.Minute,
+		Interval:        5 * time.Minute,
+		OpenTimeout:     time.Minute,
+		ReadyToTrip: func(info gcredis.Counts) bool {
+			return false // Keep counting every outcome.
+		},
+	}
+
+	var breakers []gocircuit.CircuitBreaker[int]
+	for i := 0; i < *clients; i++ {
+		rdb := redis.NewClient(&redis.Options{Addr: *addr})
+		defer rdb.Close()
+		if i == 0 {
+			err := gcredis.Reset(rdb, ctx, key, settings)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+		instanceSettings := settings
+		instanceSettings.InstanceId = fmt.Sprintf("client-%d", i)
+		breakers = append(breakers, gcredis.NewRealtimeRedisCircuitBreaker[int](rdb, key, instanceSettings))
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, *clients**goroutines**calls)
+	for _, cb := range breakers {
+		for g := 0; g < *goroutines; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for c := 0; c < *calls; c++ {
+					fail := c%2 == 1
+					_, err := cb.Protect(ctx, func() (int, error) {
+						if fail {
+							return 0, errors.New("failure")
+						}
+						return 0, nil
+					})
+					if err != nil && !fail {
+						errs <- err
+					}
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	inspector, _ := gocircuit.AsInspector(breakers[0])
+	snapshot, err := inspector.Inspect(ctx)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	total := int64(*clients * *goroutines * *calls)
+	successes := int64(*clients * *goroutines * ((*calls + 1) / 2))
+	fmt.Printf("recorded %d requests, %d successes and %d failures of %d requests and %d successes\n",
+		snapshot.Counts.Requests, snapshot.Counts.TotalSuccesses, snapshot.Counts.TotalFailures, total, successes)
+	if snapshot.Counts.Requests != total || snapshot.Counts.TotalSuccesses != successes || snapshot.Counts.TotalFailures != total-successes {
+		fmt.Println("outcomes were lost")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,86 @@
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+	"github.com/redis/go-redis/v9"
+)
+
+// Reasons recorded when an operator overrides the circuit breaker.
+const (
+	ReasonForced = gocircuit.ReasonForced
+	ReasonReset  = gocircuit.ReasonReset
+)
+
+// ForceState pins the circuit breaker stored under key in a forced state for every instance sharing it.
+// A zero expiry keeps the override until Reset is called. Only settings.OnStateChange and settings.StateListeners
+// are notified, use the Force method of a circuit breaker to also notify its own listeners and subscribers.
+func ForceState(client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings, state gocircuit.State, expiry time.Duration) error {
+	return forceState(client, ctx, key, settings, state, expiry, newInstance(key, settings))
+}
+
+func forceState(client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings, state gocircuit.State, expiry time.Duration, inst *instance) error {
+	if !state.IsForced() {
+		return fmt.Errorf("cannot force state: %s", state)
+	}
+	systime := time.Now()
+	forced := StateStruct{State: state}
+	if expiry > 0 {
+		forced.TimeOpen = systime.Add(expiry)
+	}
+	forcedString, err := StateStructToString(forced)
+	if err != nil {
+		return err
+	}
+
+	cbi, err := readInformation(client, ctx, key, systime, settings, false) // Settings may come from an operator, so keep the outcomes.
+	if err != nil {
+		return err
+	}
+
+	pipe := client.TxPipeline()
+	pipe.Set(ctx, forceKey(settings.Prefix, key), forcedString, expiry)
+	recordTransition(pipe, ctx, key, settings, cbi.State, state, cbi.Counts(), ReasonForced, systime)
+	_, err = pipe.Exec(ctx)
+	if err != nil {
+		return err
+	}
+	logTransition(ctx, key, settings, cbi.State, state, ReasonForced)
+	if cbi.State != state {
+		inst.stateChanged(cbi.State, state)
+	}
+	return nil
+}
+
+// Reset lifts any forced state and returns the circuit breaker stored under key to closed with empty counts.
+// Listeners are notified as by ForceState.
+func Reset(client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings) error {
+	return reset(client, ctx, key, settings, newInstance(key, settings))
+}
+
+func reset(client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings, inst *instance) error {
+	systime := time.Now()
+	cbi, err := getInformation(client, ctx, key, systime, settings)
+	if err != nil {
+		return err
+	}
+
+	err = clearWith(client, ctx, key, settings, func(pipe redis.Pipeliner) {
+		pipe.Del(ctx, forceKey(settings.Prefix, key))
+		pipe.Del(ctx, halfOpenKey(settings.Prefix, key))
+		pipe.Del(ctx, healthCheckKey(settings.Prefix, key))
+		pipe.Del(ctx, probeLeaseKey(settings.Prefix, key))
+		recordTransition(pipe, ctx, key, settings, cbi.State, gocircuit.StateClosed, cbi.Counts(), ReasonReset, systime)
+	})
+	if err != nil {
+		return err
+	}
+	logTransition(ctx, key, settings, cbi.State, gocircuit.StateClosed, ReasonReset)
+	if cbi.State != gocircuit.StateClosed {
+		inst.stateChanged(cbi.State, gocircuit.StateClosed)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package realtime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+type transition struct {
+	from, to gocircuit.State
+}
+
+func TestForceNotifiesBreakerListeners(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	cb := NewRealtimeRedisCircuitBreaker[int](client, "force", testSettings())
+	transitions := make(chan transition, 2)
+	cb.(gocircuit.Listenable).AddStateListener(func(from gocircuit.State, to gocircuit.State) error {
+		transitions <- transition{from, to}
+		return nil
+	})
+
+	controller := cb.(gocircuit.Controller)
+	if err := controller.Force(ctx, gocircuit.StateForcedOpen, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := controller.Reset(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []transition{
+		{gocircuit.StateClosed, gocircuit.StateForcedOpen},
+		{gocircuit.StateForcedOpen, gocircuit.StateClosed},
+	} {
+		select {
+		case got := <-transitions:
+			if got != want {
+				t.Errorf("expected %v, got %v", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("listener not notified of %v", want)
+		}
+	}
+}
+
+func TestForcePublishesStateChanges(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	cb := NewRealtimeRedisCircuitBreaker[int](client, "force", testSettings())
+	subscription := gocircuit.Merge(4, cb.(gocircuit.EventSource))
+	defer subscription.Close()
+
+	controller := cb.(gocircuit.Controller)
+	if err := controller.Force(ctx, gocircuit.StateForcedClosed, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := controller.Reset(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []transition{
+		{gocircuit.StateClosed, gocircuit.StateForcedClosed},
+		{gocircuit.StateForcedClosed, gocircuit.StateClosed},
+	} {
+		select {
+		case event := <-subscription.Events():
+			if got := (transition{event.From, event.State}); event.Kind != gocircuit.EventStateChange || got != want {
+				t.Errorf("expected a state change %v, got %v %v", want, event.Kind, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no event published for %v", want)
+		}
+	}
+}
+
+func TestForceStateKeepsOutcomes(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	settings := testSettings()
+	settings.Interval = time.Hour
+	cb := NewRealtimeRedisCircuitBreaker[int](client, "keep", settings)
+	for i := 0; i < 2; i++ {
+		_, _ = cb.Protect(ctx, func() (int, error) { return 0, nil })
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	operator := settings
+	operator.Interval = time.Millisecond // Shorter than the breaker's, as a command line flag may be.
+	if err := ForceState(client, ctx, "keep", operator, gocircuit.StateForcedClosed, 0); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := Inspect(client, ctx, "keep", settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Counts.Requests != 2 {
+		t.Errorf("expected forcing to keep 2 outcomes, got %d", snapshot.Counts.Requests)
+	}
+}
@@ -0,0 +1,138 @@
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+	"github.com/christopherdavenport/gocircuit/slogcircuit"
+	"github.com/redis/go-redis/v9"
+)
+
+func healthCheckKey(prefix string, key string) string {
+	return fmt.Sprintf("%s:healthcheck:%s", prefix, key)
+}
+
+func healthCheckInterval(settings CircuitBreakerSettings) time.Duration {
+	if settings.HealthCheckInterval > 0 {
+		return settings.HealthCheckInterval
+	}
+	return max(settings.OpenTimeout, time.Second)
+}
+
+// startHealthCheck runs the health check in the background if no other instance holds the health check lease,
+// closing the circuit breaker if it passes and it is still in oldState. The lease is kept until it expires
+// after a failed check, so checks are spaced by the interval across all instances.
+func startHealthCheck(client *redis.Client, key string, settings CircuitBreakerSettings, inst *instance, oldState StateStruct) {
+	if !inst.checking.CompareAndSwap(false, true) {
+		return // This instance is already checking.
+	}
+	go func() {
+		defer inst.checking.Store(false)
+		ctx := context.Background()
+		interval := healthCheckInterval(settings)
+		acquired, err := client.SetNX(ctx, healthCheckKey(settings.Prefix, key), settings.InstanceId, interval).Result()
+		if err != nil {
+			bookkeepingFailed(ctx, key, settings, &inst.stats, "acquire health check lease", err)
+			return
+		}
+		if !acquired {
+			return // Another instance is checking, or checked within the interval.
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, interval)
+		_, err = gocircuit.Recover(func() (struct{}, error) { return struct{}{}, settings.HealthCheck(checkCtx) })()
+		cancel()
+		if err != nil {
+			logHealthCheckFailed(ctx, key, settings, err)
+			return
+		}
+		err = closeAfterHealthCheck(client, ctx, key, settings, inst, oldState)
+		if err != nil && err != redis.TxFailedErr { // A failed transaction means the state has moved on without us.
+			bookkeepingFailed(ctx, key, settings, &inst.stats, "close after health check", err)
+		}
+	}()
+}
+
+// scheduleHealthCheck starts the health check on a timer while the circuit breaker this instance opened stays open,
+// so it recovers even if no requests arrive to trigger the check. It stops once the state is no longer open or
+// cannot be read.
+func scheduleHealthCheck(client *redis.Client, key string, settings CircuitBreakerSettings, inst *instance, state StateStruct) {
+	if settings.HealthCheck == nil || !inst.scheduled.CompareAndSwap(false, true) {
+		return // Not checking health, or this instance already has a timer running.
+	}
+	go func() {
+		defer inst.scheduled.Store(false)
+		ctx := context.Background()
+		next := state.TimeOpen
+		for {
+			time.Sleep(time.Until(next))
+			currentStateString, err := client.Get(ctx, stateKey(settings.Prefix, key)).Result()
+			if err == redis.Nil {
+				return // Expired, so closed.
+			}
+			if err != nil {
+				bookkeepingFailed(ctx, key, settings, &inst.stats, "read state for health check", err)
+				return
+			}
+			current, err := StateStructFromString(currentStateString)
+			if err != nil || current.State != gocircuit.StateOpen {
+				return
+			}
+			if current.TimeOpen.After(time.Now()) { // Reopened since, so wait for the new timeout.
+				next = current.TimeOpen
+				continue
+			}
+			startHealthCheck(client, key, settings, inst, current)
+			next = time.Now().Add(healthCheckInterval(settings))
+		}
+	}()
+}
+
+func closeAfterHealthCheck(client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings, inst *instance, oldState StateStruct) error {
+	txf := func(tx *redis.Tx) error {
+		currentStateString, err := tx.Get(ctx, stateKey(settings.Prefix, key)).Result()
+		if err != nil && err != redis.Nil {
+			return err
+		}
+		currentState := closedZero
+		if err != redis.Nil {
+			currentState, err = StateStructFromString(currentStateString)
+			if err != nil {
+				return err
+			}
+		}
+		if currentState.State != oldState.State || !currentState.TimeOpen.Equal(oldState.TimeOpen) {
+			return redis.TxFailedErr
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			queueClear(pipe, ctx, key, settings) // Within the transaction, unlike clearWith.
+			pipe.Del(ctx, healthCheckKey(settings.Prefix, key))
+			recordTransition(pipe, ctx, key, settings, oldState.State, gocircuit.StateClosed, Counts{}, ReasonHealthCheckPassed, time.Now())
+			return nil
+		})
+		return err
+	}
+
+	err := client.Watch(ctx, txf, stateKey(settings.Prefix, key))
+	if err != nil {
+		return err
+	}
+	logTransition(ctx, key, settings, oldState.State, gocircuit.StateClosed, ReasonHealthCheckPassed)
+	inst.stateChanged(oldState.State, gocircuit.StateClosed)
+	return nil
+}
+
+func logHealthCheckFailed(ctx context.Context, key string, settings CircuitBreakerSettings, err error) {
+	if settings.Logger == nil {
+		return
+	}
+	settings.Logger.LogAttrs(ctx, slog.LevelWarn, "circuit breaker health check failed",
+		slog.String(slogcircuit.BreakerKey, key),
+		instanceAttr(settings),
+		slog.Any(slogcircuit.ErrorKey, err),
+	)
+}
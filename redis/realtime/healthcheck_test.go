@@ -0,0 +1,93 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+func TestHealthCheckClearsCountWindow(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	settings := testSettings()
+	settings.WindowSize = 5
+	settings.OpenTimeout = 10 * time.Millisecond
+	settings.HealthCheck = func(ctx context.Context) error { return nil }
+	cb := NewRealtimeRedisCircuitBreaker[int](client, "healthcheck", settings)
+	for i := 0; i < 4; i++ { // The fourth request trips the circuit breaker.
+		_, _ = cb.Protect(ctx, func() (int, error) { return 0, errors.New("failed") })
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		snapshot, err := Inspect(client, ctx, "healthcheck", settings)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if snapshot.State == gocircuit.StateClosed {
+			if snapshot.Counts.Requests != 0 {
+				t.Errorf("expected the health check to clear the last outcomes, got %+v", snapshot.Counts)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("health check did not close the circuit breaker, still %s", snapshot.State)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHealthCheckWithoutTraffic(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	settings := testSettings()
+	settings.OpenTimeout = 10 * time.Millisecond
+	settings.HealthCheck = func(ctx context.Context) error { return nil }
+	cb := NewRealtimeRedisCircuitBreaker[int](client, "idle", settings)
+	for i := 0; i < 4; i++ { // The fourth request trips the circuit breaker.
+		_, _ = cb.Protect(ctx, func() (int, error) { return 0, errors.New("failed") })
+	}
+
+	// No further requests are made, so only the timer of this instance can start the health check.
+	deadline := time.Now().Add(time.Second)
+	for {
+		snapshot, err := Inspect(client, ctx, "idle", settings)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if snapshot.State == gocircuit.StateClosed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("health check did not close the idle circuit breaker, still %s", snapshot.State)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBookkeepingErrorsLoseNoOutcome(t *testing.T) {
+	server, client := newTestClient(t)
+	settings := testSettings()
+	settings.OpenTimeout = 10 * time.Millisecond
+	settings.HealthCheck = func(ctx context.Context) error { return nil }
+	var storeErrors atomic.Int64
+	settings.OnStoreError = func(operation string, err error) { storeErrors.Add(1) }
+	cb := NewRealtimeRedisCircuitBreaker[int](client, "bookkeeping", settings)
+	trip(cb)
+	server.Close() // The health check timer then fails to read the state.
+
+	deadline := time.Now().Add(time.Second)
+	for cb.(StatsProvider).Stats().BookkeepingErrors == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected a bookkeeping error")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if stats := cb.(StatsProvider).Stats(); stats.LostOutcomes != 0 || storeErrors.Load() != 0 {
+		t.Errorf("expected no lost outcomes nor store errors, got %+v and %d", stats, storeErrors.Load())
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/christopherdavenport/gocircuit"
@@ -27,6 +28,12 @@ func (e CircuitBreakerError) Error() string {
 	return string(e)
 }
 
+// Is reports CircuitBreakerOpen as a gocircuit.ErrOpen.
+func (e CircuitBreakerError) Is(target error) bool {
+	return e == CircuitBreakerOpen && target == gocircuit.ErrOpen
+}
+
+// CircuitBreakerOpen is returned by Protect when it rejects a request. It matches gocircuit.ErrOpen with errors.Is.
 const CircuitBreakerOpen CircuitBreakerError = "circuit breaker is open/half-open"
 
 // type CircuitBreaker interface {
@@ -41,11 +48,13 @@ func StateStructToString(s StateStruct) (string, error) {
 
 	switch s.State {
 	case gocircuit.StateClosed:
-		stateString = fmt.Sprintf("%s %d", stateString, s.TimeOpen.UnixNano())
+		stateString = fmt.Sprintf("%s %s", stateString, timeToString(s.TimeOpen))
 	case gocircuit.StateHalfOpen:
-		stateString = fmt.Sprintf("%s %d", stateString, s.TimeOpen.UnixNano())
+		stateString = fmt.Sprintf("%s %s", stateString, timeToString(s.TimeOpen))
 	case gocircuit.StateOpen:
-		stateString = fmt.Sprintf("%s %d", stateString, s.TimeOpen.UnixNano())
+		stateString = fmt.Sprintf("%s %s", stateString, timeToString(s.TimeOpen))
+	case gocircuit.StateForcedOpen, gocircuit.StateForcedClosed:
+		stateString = fmt.Sprintf("%s %s", stateString, timeToString(s.TimeOpen)) // TimeOpen is when the override expires.
 	default:
 		return "", fmt.Errorf("unknown state: %d", s.State)
 	}
@@ -99,6 +108,18 @@ func halfOpenKey(prefix string, key string) string {
 	return fmt.Sprintf("%s:half:%s", prefix, key)
 }
 
+func forceKey(prefix string, key string) string {
+	return fmt.Sprintf("%s:force:%s", prefix, key)
+}
+
+// timeToString encodes t as unix nanoseconds, with the zero time encoded as 0.
+func timeToString(t time.Time) string {
+	if t.IsZero() {
+		return "0"
+	}
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
 func timeFromString(s string) (time.Time, error) {
 	if s == "" {
 		return time.Time{}, fmt.Errorf("empty string")
@@ -107,6 +128,9 @@ func timeFromString(s string) (time.Time, error) {
 	if err != nil {
 		return time.Time{}, fmt.Errorf("invalid time string: %s", s)
 	}
+	if i == 0 {
+		return time.Time{}, nil
+	}
 	return time.Unix(0, i), nil
 }
 
@@ -120,16 +144,23 @@ type circuitBreakerInfo struct {
 	TotalFailures        int64
 	ConsecutiveSuccesses int64
 	ConsecutiveFailures  int64
+
+	Weighted *gocircuit.WeightedCounts // Nil unless the settings are Weighted.
 }
 
 func (cbi circuitBreakerInfo) Counts() Counts {
-	return Counts{
+	counts := Counts{
 		Requests:             cbi.TotalRequests,
 		TotalSuccesses:       cbi.TotalSuccesses,
 		TotalFailures:        cbi.TotalFailures,
 		ConsecutiveSuccesses: cbi.ConsecutiveSuccesses,
 		ConsecutiveFailures:  cbi.ConsecutiveFailures,
 	}
+	if cbi.Weighted == nil {
+		return counts.WithUnitWeights()
+	}
+	counts.Weighted = *cbi.Weighted
+	return counts
 }
 
 func (cbi circuitBreakerInfo) String() string {
@@ -137,17 +168,34 @@ func (cbi circuitBreakerInfo) String() string {
 }
 
 func getInformation(client *redis.Client, ctx context.Context, key string, systime time.Time, settings CircuitBreakerSettings) (*circuitBreakerInfo, error) {
+	return readInformation(client, ctx, key, systime, settings, true)
+}
+
+// readInformation reads the state and the counts within the window of the circuit breaker stored under key,
+// first removing the outcomes that have left the window if trim is set.
+func readInformation(client *redis.Client, ctx context.Context, key string, systime time.Time, settings CircuitBreakerSettings, trim bool) (*circuitBreakerInfo, error) {
 	pipe := client.Pipeline()
 
 	id := uuid.New()
 
-	clearTimings(pipe, ctx, key, systime, settings)
+	if trim {
+		clearTimings(pipe, ctx, key, systime, settings)
+	}
+	windowStart := "(" + strconv.FormatInt(systime.UnixNano()-settings.Interval.Nanoseconds(), 10)
 	stateCmd := pipe.Get(ctx, stateKey(settings.Prefix, key))
-	requestCountCmd := pipe.ZCard(ctx, requestKey(settings.Prefix, key))
-	successCountCmd := pipe.ZCard(ctx, successKey(settings.Prefix, key))
-	failureCountCmd := pipe.ZCard(ctx, failureKey(settings.Prefix, key))
-	consecutiveSuccessCountCmd := pipe.ZCard(ctx, consecutiveSuccessKey(settings.Prefix, key))
-	consecutiveFailureCountCmd := pipe.ZCard(ctx, consecutiveFailureKey(settings.Prefix, key))
+	forceCmd := pipe.Get(ctx, forceKey(settings.Prefix, key))
+	requestCountCmd := pipe.ZCount(ctx, requestKey(settings.Prefix, key), windowStart, "+inf")
+	successCountCmd := pipe.ZCount(ctx, successKey(settings.Prefix, key), windowStart, "+inf")
+	failureCountCmd := pipe.ZCount(ctx, failureKey(settings.Prefix, key), windowStart, "+inf")
+	consecutiveSuccessCountCmd := pipe.ZCount(ctx, consecutiveSuccessKey(settings.Prefix, key), windowStart, "+inf")
+	consecutiveFailureCountCmd := pipe.ZCount(ctx, consecutiveFailureKey(settings.Prefix, key), windowStart, "+inf")
+	var outcomesCmd *redis.Cmd
+	var weightCmds []*redis.SliceCmd
+	if settings.WindowSize > 0 {
+		outcomesCmd = countOutcomesScript.Eval(ctx, pipe, []string{outcomesKey(settings.Prefix, key)})
+	} else if settings.Weighted {
+		weightCmds = readWeights(pipe, ctx, key, settings, systime)
+	}
 
 	_, err := pipe.Exec(ctx)
 
@@ -170,6 +218,17 @@ func getInformation(client *redis.Client, ctx context.Context, key string, systi
 		}
 	}
 
+	forceString, err := forceCmd.Result()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+	if err == nil { // A forced state overrides whatever state the circuit breaker is in.
+		stateStruct, err = StateStructFromString(forceString)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	requestCount, err := requestCountCmd.Result()
 	if err != nil {
 		return nil, err
@@ -195,8 +254,31 @@ func getInformation(client *redis.Client, ctx context.Context, key string, systi
 		return nil, err
 	}
 
+	var weighted *gocircuit.WeightedCounts
+	if weightCmds != nil {
+		weighted, err = sumWeights(weightCmds)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if outcomesCmd != nil { // Count the last WindowSize outcomes instead of the sorted sets.
+		values, err := outcomesCmd.StringSlice()
+		if err != nil {
+			return nil, err
+		}
+		counts, err := countsFromStrings(values)
+		if err != nil {
+			return nil, err
+		}
+		requestCount, successCount, failureCount = counts.Requests, counts.TotalSuccesses, counts.TotalFailures
+		consecutiveSuccessCount, consecutiveFailureCount = counts.ConsecutiveSuccesses, counts.ConsecutiveFailures
+		weighted = &counts.Weighted
+	}
+
 	return &circuitBreakerInfo{
 		Id:       id,
+		Weighted: weighted,
 		State:    stateStruct.State,
 		TimeOpen: stateStruct.TimeOpen,
 
@@ -210,18 +292,31 @@ func getInformation(client *redis.Client, ctx context.Context, key string, systi
 }
 
 func clear(client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings) error {
+	return clearWith(client, ctx, key, settings, func(pipe redis.Pipeliner) {})
+}
+
+// clearWith resets the circuit breaker, queueing any extra commands from f in the same pipeline.
+func clearWith(client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings, f func(pipe redis.Pipeliner)) error {
 	pipe := client.Pipeline()
+	f(pipe)
+	queueClear(pipe, ctx, key, settings)
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// queueClear queues the commands resetting the circuit breaker to closed with empty counts in pipe.
+func queueClear(pipe redis.Pipeliner, ctx context.Context, key string, settings CircuitBreakerSettings) {
 	pipe.Del(ctx, stateKey(settings.Prefix, key))
 	pipe.Del(ctx, requestKey(settings.Prefix, key))
 	pipe.Del(ctx, failureKey(settings.Prefix, key))
 	pipe.Del(ctx, successKey(settings.Prefix, key))
 	pipe.Del(ctx, consecutiveSuccessKey(settings.Prefix, key))
 	pipe.Del(ctx, consecutiveFailureKey(settings.Prefix, key))
-	_, err := pipe.Exec(ctx)
-	if err != nil {
-		return err
-	}
-	return nil
+	pipe.Del(ctx, outcomesKey(settings.Prefix, key))
+	queueClearWeights(pipe, ctx, key, settings, time.Now())
 }
 
 func clearTimings(pipe redis.Pipeliner, ctx context.Context, key string, systime time.Time, settings CircuitBreakerSettings) {
@@ -234,7 +329,7 @@ func clearTimings(pipe redis.Pipeliner, ctx context.Context, key string, systime
 
 }
 
-func registerSuccess(client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings) error {
+func registerSuccess(client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings, weight float64) error {
 	pipe := client.Pipeline()
 	stateStruct := StateStruct{
 		State:    gocircuit.StateClosed,
@@ -246,17 +341,26 @@ func registerSuccess(client *redis.Client, ctx context.Context, key string, sett
 	}
 
 	systime := time.Now()
+	member := outcomeMember(settings, systime, weight)
 	pipe.Set(ctx, stateKey(settings.Prefix, key), stateStructString, settings.RedisKeyTimeout)
+	if settings.WindowSize > 0 {
+		pushOutcome(pipe, ctx, key, settings, true, member)
+		_, err = pipe.Exec(ctx)
+		return err
+	}
+	if settings.Weighted {
+		addWeight(pipe, ctx, key, settings, systime, true, weight)
+	}
 	pipe.ZAdd(ctx, requestKey(settings.Prefix, key), redis.Z{
-		Member: systime,
+		Member: member,
 		Score:  float64(systime.UnixNano()),
 	})
 	pipe.ZAdd(ctx, successKey(settings.Prefix, key), redis.Z{
-		Member: systime,
+		Member: member,
 		Score:  float64(systime.UnixNano()),
 	})
 	pipe.ZAdd(ctx, consecutiveSuccessKey(settings.Prefix, key), redis.Z{
-		Member: systime,
+		Member: member,
 		Score:  float64(systime.UnixNano()),
 	})
 	pipe.Del(ctx, consecutiveFailureKey(settings.Prefix, key))
@@ -273,21 +377,44 @@ func registerSuccess(client *redis.Client, ctx context.Context, key string, sett
 	return nil
 }
 
+// outcomeSeq distinguishes outcomes recorded by this process at the same time.
+var outcomeSeq atomic.Uint64
+
+// outcomeMember returns a sorted set member unique to this outcome, so that outcomes recorded at the same time,
+// by this instance or any other, are all counted. A weight other than one is appended after a "|".
+func outcomeMember(settings CircuitBreakerSettings, systime time.Time, weight float64) string {
+	member := fmt.Sprintf("%s:%d:%d", settings.InstanceId, systime.UnixNano(), outcomeSeq.Add(1))
+	if weight != 1 {
+		member += "|" + strconv.FormatFloat(weight, 'g', -1, 64)
+	}
+	return member
+}
+
 // How we add a failure.
-func registerFailure(client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings) error {
+func registerFailure(client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings, weight float64) error {
 	pipe := client.Pipeline()
 
 	systime := time.Now()
+	member := outcomeMember(settings, systime, weight)
+	if settings.WindowSize > 0 {
+		pushOutcome(pipe, ctx, key, settings, false, member)
+		pipe.PExpire(ctx, stateKey(settings.Prefix, key), settings.RedisKeyTimeout)
+		_, err := pipe.Exec(ctx)
+		return err
+	}
+	if settings.Weighted {
+		addWeight(pipe, ctx, key, settings, systime, false, weight)
+	}
 	pipe.ZAdd(ctx, requestKey(settings.Prefix, key), redis.Z{
-		Member: systime,
+		Member: member,
 		Score:  float64(systime.UnixNano()),
 	})
 	pipe.ZAdd(ctx, failureKey(settings.Prefix, key), redis.Z{
-		Member: systime,
+		Member: member,
 		Score:  float64(systime.UnixNano()),
 	})
 	pipe.ZAdd(ctx, consecutiveFailureKey(settings.Prefix, key), redis.Z{
-		Member: systime,
+		Member: member,
 		Score:  float64(systime.UnixNano()),
 	})
 	pipe.Del(ctx, consecutiveSuccessKey(settings.Prefix, key))
@@ -311,38 +438,42 @@ func empty[A any]() A {
 	return a
 }
 
-func runClosed[A any](client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings, action func() (A, error)) (A, error) {
-	out, initialErr := action()
-	if initialErr != nil && !settings.IsSuccessful(initialErr) {
-		// TODO - Add logging
-		_ = registerFailure(client, ctx, key, settings)
-	} else {
-		_ = registerSuccess(client, ctx, key, settings)
-	}
-	return out, initialErr
+func runClosed[A any](client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings, inst *instance, classify gocircuit.Classifier[A], action func() (A, error)) (A, error) {
+	out, initialErr, outcome := runAction(ctx, settings, inst, classify, gocircuit.StateClosed, action)
+	weight := gocircuit.Weight(ctx, settings.Weight, initialErr)
+	var storeErr error
+	switch outcome {
+	case gocircuit.OutcomeFailure:
+		storeErr = storeFailed(ctx, key, settings, &inst.stats, "register failure", registerFailure(client, ctx, key, settings, weight))
+	case gocircuit.OutcomeSuccess:
+		storeErr = storeFailed(ctx, key, settings, &inst.stats, "register success", registerSuccess(client, ctx, key, settings, weight))
+	}
+	return out, withStoreError(initialErr, storeErr)
 }
 
-func runOpenAndHalf[A any]() (A, error, bool) {
+func runOpenAndHalf[A any](ctx context.Context, key string, settings CircuitBreakerSettings, inst *instance, state gocircuit.State) (A, error, bool) {
+	rejected(ctx, key, settings, inst, state, CircuitBreakerOpen)
 	return empty[A](), CircuitBreakerOpen, false
 }
-func setToOpen(client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings, oldState StateStruct) (error, bool) {
+func setToOpen(client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings, inst *instance, oldState StateStruct, counts Counts, reason string, openTimeout time.Duration) (error, bool) {
 	systime := time.Now() // TODO - Use last operation time, rather than now
 
 	state := StateStruct{
 		State:    gocircuit.StateOpen,
-		TimeOpen: systime.Add(settings.OpenTimeout),
+		TimeOpen: systime.Add(openTimeout),
 	}
 	stateString, err := StateStructToString(state)
 	if err != nil {
 		return err, false
 	}
 
+	var from gocircuit.State
 	txf := func(tx *redis.Tx) error {
 		currentStateString, err := tx.Get(ctx, stateKey(settings.Prefix, key)).Result()
 		if err != nil && err != redis.Nil {
 			return err
 		}
-		var currentState StateStruct
+		currentState := closedZero
 		if err != redis.Nil {
 			currentState, err = StateStructFromString(currentStateString)
 			if err != nil {
@@ -350,17 +481,17 @@ func setToOpen(client *redis.Client, ctx context.Context, key string, settings C
 			}
 		}
 		// fmt.Println("currentState:", currentState, ", oldState:", oldState, "state:", state)
-		if currentState.State != oldState.State && currentState.TimeOpen != oldState.TimeOpen && err != redis.Nil {
-
-			return redis.TxFailedErr // This triggers same behavior as a failed transaction.
+		if currentState.State != oldState.State || !currentState.TimeOpen.Equal(oldState.TimeOpen) {
+			return redis.TxFailedErr // Another instance or an operator has moved the state on since it was read, so read it again.
 		}
 
 		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
 			pipe.Set(ctx, stateKey(settings.Prefix, key), stateString, settings.RedisKeyTimeout)
+			recordTransition(pipe, ctx, key, settings, currentState.State, state.State, counts, reason, systime)
 			return nil
 		})
 		if err == nil {
-			settings.OnStateChange(currentState.State, state.State)
+			from = currentState.State
 		}
 
 		return err
@@ -373,21 +504,42 @@ func setToOpen(client *redis.Client, ctx context.Context, key string, settings C
 		}
 		return err, false
 	}
+	logTransition(ctx, key, settings, from, state.State, reason)
+	inst.stateChanged(from, state.State)
+	scheduleHealthCheck(client, key, settings, inst, state)
 	return CircuitBreakerOpen, false
 }
 
-func setToHalfOpen[A any](client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings, oldState StateStruct, f func() (A, error)) (A, error, bool) {
+// probe runs the action as the half-open probe if this instance can take the probe lease, and rejects it otherwise.
+func probe[A any](client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings, inst *instance, classify gocircuit.Classifier[A], oldState StateStruct, counts Counts, f func() (A, error)) (A, error, bool) {
+	token, err := acquireProbeLease(client, ctx, key, settings)
+	if err != nil {
+		return empty[A](), err, false
+	}
+	if token == "" { // Another instance is probing.
+		return runOpenAndHalf[A](ctx, key, settings, inst, oldState.State)
+	}
+	value, err, retry := setToHalfOpen[A](client, ctx, key, settings, inst, classify, oldState, counts, f)
+	releaseErr := releaseProbeLease(client, context.WithoutCancel(ctx), key, settings, token)
+	if releaseErr != nil && !retry { // Left to expire, which only delays the next probe.
+		bookkeepingFailed(ctx, key, settings, &inst.stats, "release probe lease", releaseErr)
+	}
+	return value, err, retry
+}
+
+func setToHalfOpen[A any](client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings, inst *instance, classify gocircuit.Classifier[A], oldState StateStruct, counts Counts, f func() (A, error)) (A, error, bool) {
 	systime := time.Now() // TODO - Use last operation time, rather than now
 
 	state := StateStruct{
 		State:    gocircuit.StateHalfOpen,
-		TimeOpen: systime.Add(settings.OpenTimeout),
+		TimeOpen: systime.Add(probeLeaseDuration(settings)), // When the probe is presumed dead, see probe.
 	}
 	stateString, err := StateStructToString(state)
 	if err != nil {
 		return empty[A](), err, false
 	}
 
+	var from gocircuit.State
 	txf := func(tx *redis.Tx) error {
 		currentStateString, err := tx.Get(ctx, stateKey(settings.Prefix, key)).Result()
 		if err != nil && err != redis.Nil {
@@ -402,9 +554,8 @@ func setToHalfOpen[A any](client *redis.Client, ctx context.Context, key string,
 			}
 		}
 		// fmt.Println("currentState:", currentState, ", oldState:", oldState, "state:", state)
-		if currentState.State != oldState.State && currentState.TimeOpen != oldState.TimeOpen && err != redis.Nil {
-
-			return redis.TxFailedErr // This triggers same behavior as a failed transaction.
+		if currentState.State != oldState.State || !currentState.TimeOpen.Equal(oldState.TimeOpen) {
+			return redis.TxFailedErr // Another instance has moved the state on since it was read, so read it again.
 		}
 
 		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
@@ -413,38 +564,52 @@ func setToHalfOpen[A any](client *redis.Client, ctx context.Context, key string,
 				Member: systime,
 				Score:  float64(systime.UnixNano()),
 			})
+			recordTransition(pipe, ctx, key, settings, currentState.State, state.State, counts, ReasonOpenTimeout, systime)
 			return nil
 		})
 		if err == nil {
-			settings.OnStateChange(currentState.State, state.State)
+			from = currentState.State
 		}
 		return err
 	}
 
 	err = client.Watch(ctx, txf, stateKey(settings.Prefix, key), halfOpenKey(settings.Prefix, key))
 	if err != nil {
-		return empty[A](), err, false
-	}
-	value, initErr := f()
-	if initErr != nil && !settings.IsSuccessful(initErr) {
-		err, retry := setToOpen(client, ctx, key, settings, state)
-
-		if err != redis.TxFailedErr && err != nil {
-			return value, err, false
+		return empty[A](), err, err == redis.TxFailedErr // The action has not run, so it is safe to retry.
+	}
+	logTransition(ctx, key, settings, from, state.State, ReasonOpenTimeout)
+	inst.stateChanged(from, state.State)
+	value, initErr, outcome := runAction(ctx, settings, inst, classify, state.State, f)
+	// The action has run, so from here on the probe must never be retried and its half-open entry must always be released.
+	if outcome != gocircuit.OutcomeSuccess {
+		probeCounts := Counts{Requests: 1, TotalFailures: 1, ConsecutiveFailures: 1}
+		reason, openTimeout := ReasonProbeFailed, settings.OpenTimeout
+		if outcome == gocircuit.OutcomeIgnore {
+			// The probe was inconclusive, so reopen the circuit breaker ready for the next caller to probe.
+			probeCounts = Counts{Requests: 1}
+			reason, openTimeout = ReasonProbeIgnored, 0
 		}
-		err = client.ZRem(ctx, halfOpenKey(settings.Prefix, key), systime).Err()
-		if err != nil {
-			return value, err, retry
+		err, _ := setToOpen(client, ctx, key, settings, inst, state, probeCounts, reason, openTimeout)
+		if err == CircuitBreakerOpen || err == redis.TxFailedErr { // Reopened, or another instance has moved the state on.
+			err = client.ZRem(ctx, halfOpenKey(settings.Prefix, key), systime).Err()
 		}
-		return value, err, retry
+		return value, withStoreError(initErr, storeFailed(ctx, key, settings, &inst.stats, "reopen after "+reason, err)), false
+	}
+	err = clearWith(client, ctx, key, settings, func(pipe redis.Pipeliner) {
+		pipe.ZRem(ctx, halfOpenKey(settings.Prefix, key), systime)
+		probeCounts := Counts{Requests: 1, TotalSuccesses: 1, ConsecutiveSuccesses: 1}
+		recordTransition(pipe, ctx, key, settings, state.State, gocircuit.StateClosed, probeCounts, ReasonProbeSucceeded, time.Now())
+	})
+	if err != nil {
+		return value, withStoreError(initErr, storeFailed(ctx, key, settings, &inst.stats, "clear", err)), false
 	}
-	_ = clear(client, ctx, key, settings)
-	settings.OnStateChange(state.State, gocircuit.StateClosed)
+	logTransition(ctx, key, settings, state.State, gocircuit.StateClosed, ReasonProbeSucceeded)
+	inst.stateChanged(state.State, gocircuit.StateClosed)
 
 	return value, initErr, false
 }
 
-func protectInternal[A any](client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings, action func() (A, error)) (A, error, bool) {
+func protectInternal[A any](client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings, inst *instance, classify gocircuit.Classifier[A], action func() (A, error)) (A, error, bool) {
 	now := time.Now()
 	cbi, err := getInformation(client, ctx, key, now, settings)
 	if err != nil {
@@ -452,41 +617,61 @@ func protectInternal[A any](client *redis.Client, ctx context.Context, key strin
 	}
 	// fmt.Println(cbi)
 
-	if cbi.State == gocircuit.StateClosed {
+	if cbi.State == gocircuit.StateForcedClosed {
+		a, err, _ := runAction(ctx, settings, inst, classify, cbi.State, action) // Forced closed disables the circuit breaker, so nothing is counted.
+		return a, err, false
+	} else if cbi.State == gocircuit.StateClosed {
 		if !settings.ReadyToTrip(cbi.Counts()) { // If Closed and not ready to trip then run the action.
 			// fmt.Println("Running Closed")
-			a, err := runClosed(client, ctx, key, settings, action)
+			a, err := runClosed(client, ctx, key, settings, inst, classify, action)
 			return a, err, false
 		}
 		// Ready to Trip
 		// fmt.Println("Ready to Trip")
-		err, retry := setToOpen(client, ctx, key, settings, StateStruct{State: cbi.State, TimeOpen: cbi.TimeOpen})
+		err, retry := setToOpen(client, ctx, key, settings, inst, StateStruct{State: cbi.State, TimeOpen: cbi.TimeOpen}, cbi.Counts(), ReasonReadyToTrip, settings.OpenTimeout)
 		if err != nil {
 			// fmt.Println("Error from setToOpen:", err)
+			if err == CircuitBreakerOpen {
+				return runOpenAndHalf[A](ctx, key, settings, inst, gocircuit.StateOpen)
+			}
 			return empty[A](), err, retry
 		}
-		return empty[A](), CircuitBreakerOpen, false
+		return runOpenAndHalf[A](ctx, key, settings, inst, gocircuit.StateOpen)
 	} else if cbi.State == gocircuit.StateOpen {
 		systime := time.Now()
 		diff := cbi.TimeOpen.Sub(systime)
-		if diff <= 0 { // If Open and time open has exceeded the OpenTimeout then attempt to change to Half Open
+		if diff <= 0 && settings.HealthCheck != nil { // Recover by health check rather than by probing with this request.
+			startHealthCheck(client, key, settings, inst, StateStruct{State: cbi.State, TimeOpen: cbi.TimeOpen})
+		} else if diff <= 0 { // If Open and time open has exceeded the OpenTimeout then attempt to change to Half Open
 			// fmt.Println("Changing to Half Open")
-			return setToHalfOpen[A](client, ctx, key, settings, StateStruct{State: cbi.State, TimeOpen: cbi.TimeOpen}, action)
+			return probe[A](client, ctx, key, settings, inst, classify, StateStruct{State: cbi.State, TimeOpen: cbi.TimeOpen}, cbi.Counts(), action)
 
 			// Change to Half Open
 		}
+	} else if cbi.State == gocircuit.StateHalfOpen && !cbi.TimeOpen.After(now) {
+		// The probe has outlived its lease, so its instance is presumed dead and another may probe instead.
+		return probe[A](client, ctx, key, settings, inst, classify, StateStruct{State: cbi.State, TimeOpen: cbi.TimeOpen}, cbi.Counts(), action)
 	}
 
-	return runOpenAndHalf[A]()
+	return runOpenAndHalf[A](ctx, key, settings, inst, cbi.State)
 
 }
 
-func protect[A any](client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings, action func() (A, error)) (A, error) {
-	var value A
-	var err error
-	loop := true
-	for loop {
-		value, err, loop = protectInternal[A](client, ctx, key, settings, action)
+func protect[A any](client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings, inst *instance, classify gocircuit.Classifier[A], action func() (A, error)) (A, error) {
+	retries := contentionRetries(settings)
+	for attempt := 1; ; attempt++ {
+		value, err, retry := protectInternal[A](client, ctx, key, settings, inst, classify, action)
+		if !retry {
+			return value, err
+		}
+		inst.stats.contentions.Add(1)
+		if attempt > retries {
+			inst.stats.contentionFailures.Add(1)
+			return value, &ContentionError{Key: key, Attempts: attempt}
+		}
+		logRetry(ctx, key, settings, attempt)
+		if err := sleep(ctx, contentionBackoff(settings, attempt)); err != nil {
+			return value, err
+		}
 	}
-	return value, err
 }
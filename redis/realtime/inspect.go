@@ -0,0 +1,36 @@
+package realtime
+
+import (
+	"context"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+	"github.com/redis/go-redis/v9"
+)
+
+// The number of transitions included in a Snapshot when auditing is enabled.
+const inspectTransitions = 10
+
+// Inspect returns the current state and windowed counts of the circuit breaker stored under key,
+// along with its most recent transitions if auditing is enabled. It never modifies the circuit breaker, so outcomes
+// outside of settings.Interval are left for the instances protecting requests to remove.
+func Inspect(client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings) (gocircuit.Snapshot, error) {
+	cbi, err := readInformation(client, ctx, key, time.Now(), settings, false)
+	if err != nil {
+		return gocircuit.Snapshot{}, err
+	}
+	snapshot := gocircuit.Snapshot{
+		State:  cbi.State,
+		Counts: cbi.Counts(),
+	}
+	if cbi.State != gocircuit.StateClosed {
+		snapshot.OpenUntil = cbi.TimeOpen
+	}
+	if settings.AuditMaxLen > 0 {
+		snapshot.Transitions, err = ReadTransitions(client, ctx, key, settings, inspectTransitions)
+		if err != nil {
+			return gocircuit.Snapshot{}, err
+		}
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,39 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInspectLeavesWindowUntouched(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	settings := testSettings()
+	cb := NewRealtimeRedisCircuitBreaker[int](client, "inspect", settings)
+	for _, err := range []error{nil, errors.New("failed")} {
+		_, _ = cb.Protect(ctx, func() (int, error) { return 0, err })
+	}
+
+	short := settings
+	short.Interval = time.Nanosecond // As an operator passing the wrong interval would.
+	snapshot, err := Inspect(client, ctx, "inspect", short)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Counts.Requests != 0 {
+		t.Errorf("expected no requests within a nanosecond, got %d", snapshot.Counts.Requests)
+	}
+
+	snapshot, err = Inspect(client, ctx, "inspect", settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := snapshot.Counts; c.Requests != 2 || c.TotalSuccesses != 1 || c.TotalFailures != 1 || c.ConsecutiveFailures != 1 {
+		t.Errorf("expected the outcomes to survive inspection, got %+v", c)
+	}
+	if n := client.ZCard(ctx, requestKey(settings.Prefix, "inspect")).Val(); n != 2 {
+		t.Errorf("expected 2 requests stored, got %d", n)
+	}
+}
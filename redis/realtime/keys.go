@@ -0,0 +1,52 @@
+package realtime
+
+import (
+	"strings"
+)
+
+// Keys are the Redis keys holding the state of a single circuit breaker.
+type Keys struct {
+	State                string // The StateStruct of the circuit breaker.
+	Force                string // The forced StateStruct, if an operator has overridden the state.
+	Requests             string // Sorted set of requests in the window, scored by unix nanoseconds.
+	Successes            string // Sorted set of successes in the window.
+	Failures             string // Sorted set of failures in the window.
+	ConsecutiveSuccesses string // Sorted set of successes since the last failure.
+	ConsecutiveFailures  string // Sorted set of failures since the last success.
+	HalfOpen             string // Sorted set of in-flight half-open probes.
+	Outcomes             string // List of the last WindowSize outcomes, newest first, if counting by WindowSize.
+	Weights              string // Prefix of the hashes of weight sums per time bucket, followed by ":" and the bucket, if Weighted.
+	Audit                string // Stream of transitions.
+	HealthCheck          string // The lease of the instance running the health check.
+	ProbeLease           string // The lease of the instance running the half-open probe.
+}
+
+// KeysFor returns the Redis keys of the circuit breaker stored under key.
+func KeysFor(prefix string, key string) Keys {
+	return Keys{
+		State:                stateKey(prefix, key),
+		Force:                forceKey(prefix, key),
+		Requests:             requestKey(prefix, key),
+		Successes:            successKey(prefix, key),
+		Failures:             failureKey(prefix, key),
+		ConsecutiveSuccesses: consecutiveSuccessKey(prefix, key),
+		ConsecutiveFailures:  consecutiveFailureKey(prefix, key),
+		HalfOpen:             halfOpenKey(prefix, key),
+		Outcomes:             outcomesKey(prefix, key),
+		Weights:              weightsKey(prefix, key),
+		Audit:                auditKey(prefix, key),
+		HealthCheck:          healthCheckKey(prefix, key),
+		ProbeLease:           probeLeaseKey(prefix, key),
+	}
+}
+
+// KeyFromStateKey returns the circuit breaker key of a state or force key, such as those found by
+// scanning for KeysFor(prefix, "*").State.
+func KeyFromStateKey(prefix string, redisKey string) (string, bool) {
+	for _, kind := range []string{"state", "force"} {
+		if key, ok := strings.CutPrefix(redisKey, prefix+":"+kind+":"); ok {
+			return key, true
+		}
+	}
+	return "", false
+}
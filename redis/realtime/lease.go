@@ -0,0 +1,44 @@
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+func probeLeaseKey(prefix string, key string) string {
+	return fmt.Sprintf("%s:probe:%s", prefix, key)
+}
+
+func probeLeaseDuration(settings CircuitBreakerSettings) time.Duration {
+	if settings.ProbeLease > 0 {
+		return settings.ProbeLease
+	}
+	return max(settings.OpenTimeout, time.Second)
+}
+
+// acquireProbeLease makes this instance the only one allowed to probe until the lease is released or expires.
+// It returns the token to release the lease with, or "" if another instance holds it.
+func acquireProbeLease(client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings) (string, error) {
+	token := settings.InstanceId + ":" + uuid.NewString()
+	acquired, err := client.SetNX(ctx, probeLeaseKey(settings.Prefix, key), token, probeLeaseDuration(settings)).Result()
+	if err != nil || !acquired {
+		return "", err
+	}
+	return token, nil
+}
+
+// releaseScript deletes the lease in KEYS[1] only if it is still held by ARGV[1].
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+func releaseProbeLease(client *redis.Client, ctx context.Context, key string, settings CircuitBreakerSettings, token string) error {
+	return releaseScript.Run(ctx, client, []string{probeLeaseKey(settings.Prefix, key)}, token).Err()
+}
@@ -0,0 +1,62 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+func TestOneProbePerLease(t *testing.T) {
+	server, client := newTestClient(t)
+	ctx := context.Background()
+	settings := testSettings()
+	settings.OpenTimeout = 10 * time.Millisecond
+	settings.ProbeLease = 50 * time.Millisecond
+	a := NewRealtimeRedisCircuitBreaker[int](client, "lease", settings)
+	settings.InstanceId = "other"
+	b := NewRealtimeRedisCircuitBreaker[int](client, "lease", settings)
+	trip(a)
+	time.Sleep(2 * settings.OpenTimeout)
+
+	probing, release := make(chan struct{}), make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = a.Protect(ctx, func() (int, error) { // A probe whose instance hangs.
+			close(probing)
+			<-release
+			return 0, errors.New("failed")
+		})
+	}()
+	<-probing
+
+	if _, err := b.Protect(ctx, func() (int, error) {
+		t.Error("expected only one probe while the lease is held")
+		return 0, nil
+	}); !errors.Is(err, gocircuit.ErrOpen) {
+		t.Errorf("expected a rejection while the lease is held, got %v", err)
+	}
+
+	time.Sleep(settings.ProbeLease)
+	server.FastForward(settings.ProbeLease) // Expire the lease in miniredis, which keeps its own clock.
+	ran := false
+	if _, err := b.Protect(ctx, func() (int, error) {
+		ran = true
+		return 0, nil
+	}); err != nil || !ran {
+		t.Errorf("expected another instance to probe once the lease expired, got %v", err)
+	}
+	close(release)
+	<-done
+
+	snapshot, err := Inspect(client, ctx, "lease", settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.State != gocircuit.StateClosed {
+		t.Errorf("expected the successful probe to close the breaker, got %s", snapshot.State)
+	}
+}
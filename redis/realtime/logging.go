@@ -0,0 +1,52 @@
+package realtime
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/christopherdavenport/gocircuit"
+	"github.com/christopherdavenport/gocircuit/slogcircuit"
+)
+
+// instanceAttr is the attribute identifying the instance logging a record, set on every record.
+func instanceAttr(settings CircuitBreakerSettings) slog.Attr {
+	return slog.String(slogcircuit.InstanceKey, settings.InstanceId)
+}
+
+func logTransition(ctx context.Context, key string, settings CircuitBreakerSettings, from gocircuit.State, to gocircuit.State, reason string) {
+	if settings.Logger == nil {
+		return
+	}
+	slogcircuit.LogTransition(ctx, settings.Logger, key, from, to, reason, instanceAttr(settings))
+}
+
+func logRejected(ctx context.Context, key string, settings CircuitBreakerSettings, state gocircuit.State, err error) {
+	if settings.Logger == nil {
+		return
+	}
+	slogcircuit.LogRejected(ctx, settings.Logger, key, err, slog.String(slogcircuit.StateKey, state.String()), instanceAttr(settings))
+}
+
+// logStoreError logs a failure to update Redis.
+func logStoreError(ctx context.Context, key string, settings CircuitBreakerSettings, operation string, err error) {
+	if settings.Logger == nil || err == nil {
+		return
+	}
+	settings.Logger.LogAttrs(ctx, slog.LevelError, "circuit breaker failed to update redis",
+		slog.String(slogcircuit.BreakerKey, key),
+		instanceAttr(settings),
+		slog.String(slogcircuit.OperationKey, operation),
+		slog.Any(slogcircuit.ErrorKey, err),
+	)
+}
+
+func logRetry(ctx context.Context, key string, settings CircuitBreakerSettings, attempt int) {
+	if settings.Logger == nil {
+		return
+	}
+	settings.Logger.LogAttrs(ctx, slog.LevelDebug, "circuit breaker retrying after concurrent state change",
+		slog.String(slogcircuit.BreakerKey, key),
+		instanceAttr(settings),
+		slog.Int(slogcircuit.AttemptKey, attempt),
+	)
+}
@@ -0,0 +1,64 @@
+package realtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/christopherdavenport/gocircuit/slogcircuit"
+)
+
+// lockedBuffer collects log output written from any goroutine.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// records decodes the JSON records written so far.
+func (b *lockedBuffer) records(t *testing.T) []map[string]any {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var records []map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(b.buf.Bytes()))
+	for decoder.More() {
+		var record map[string]any
+		if err := decoder.Decode(&record); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestEveryRecordNamesInstance(t *testing.T) {
+	_, client := newTestClient(t)
+	logs := &lockedBuffer{}
+	settings := testSettings()
+	settings.Logger = slog.New(slog.NewJSONHandler(logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	cb := NewRealtimeRedisCircuitBreaker[int](client, "logging", settings)
+
+	trip(cb)
+	_, _ = cb.Protect(context.Background(), func() (int, error) { return 0, nil })
+
+	messages := map[string]int{}
+	for _, record := range logs.records(t) {
+		message, _ := record[slog.MessageKey].(string)
+		messages[message]++
+		if record[slogcircuit.InstanceKey] != "test" || record[slogcircuit.BreakerKey] != "logging" {
+			t.Errorf("expected the instance and breaker on %v", record)
+		}
+	}
+	if messages[slogcircuit.MessageTransition] != 1 || messages[slogcircuit.MessageRejected] != 2 {
+		t.Errorf("expected 1 transition and 2 rejections, got %v", messages)
+	}
+}
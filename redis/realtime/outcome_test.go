@@ -0,0 +1,57 @@
+package realtime
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOutcomeMembersUniqueAtSameTime(t *testing.T) {
+	settings := testSettings()
+	systime := time.Unix(0, 42)
+	var mu sync.Mutex
+	seen := map[string]bool{}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				member := outcomeMember(settings, systime, 1)
+				mu.Lock()
+				if seen[member] {
+					t.Errorf("duplicate member %q", member)
+				}
+				seen[member] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestConcurrentOutcomesAllCounted(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	settings := testSettings() // Every breaker shares the InstanceId.
+	settings.ReadyToTrip = func(Counts) bool { return false }
+	const goroutines, calls = 8, 25
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		cb := NewRealtimeRedisCircuitBreaker[int](client, "concurrent", settings)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < calls; j++ {
+				if _, err := cb.Protect(ctx, func() (int, error) { return 0, nil }); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if n := client.ZCard(ctx, requestKey(settings.Prefix, "concurrent")).Val(); n != goroutines*calls {
+		t.Errorf("expected %d requests, got %d", goroutines*calls, n)
+	}
+}
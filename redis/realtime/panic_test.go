@@ -0,0 +1,55 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+func TestPanickingProbeReopens(t *testing.T) {
+	for _, mode := range []gocircuit.PanicMode{gocircuit.PanicReturnError, gocircuit.PanicRepanic} {
+		server, client := newTestClient(t)
+		ctx := context.Background()
+		settings := testSettings()
+		settings.OpenTimeout = 10 * time.Millisecond
+		settings.PanicMode = mode
+		cb := NewRealtimeRedisCircuitBreaker[int](client, "panic", settings)
+		trip(cb)
+		time.Sleep(20 * time.Millisecond) // Ready for a probe.
+
+		var err error
+		recovered := func() (recovered any) {
+			defer func() { recovered = recover() }()
+			_, err = cb.Protect(ctx, func() (int, error) { panic("probe") })
+			return nil
+		}()
+
+		var panicErr *gocircuit.PanicError
+		switch mode {
+		case gocircuit.PanicReturnError:
+			if !errors.As(err, &panicErr) || panicErr.Value != "probe" || recovered != nil {
+				t.Errorf("expected a *PanicError, got %v and panic %v", err, recovered)
+			}
+		case gocircuit.PanicRepanic:
+			if recovered != "probe" {
+				t.Errorf("expected the panic again, got %v", recovered)
+			}
+		}
+		snapshot, err := cb.(gocircuit.Inspector).Inspect(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if snapshot.State != gocircuit.StateOpen {
+			t.Errorf("mode %d: expected the panicking probe to reopen the breaker, got %s", mode, snapshot.State)
+		}
+		if n, _ := client.ZCard(ctx, halfOpenKey(settings.Prefix, "panic")).Result(); n != 0 {
+			t.Errorf("mode %d: expected the half-open entry released, got %d", mode, n)
+		}
+		if server.Exists(probeLeaseKey(settings.Prefix, "panic")) {
+			t.Errorf("mode %d: expected the probe lease released", mode)
+		}
+	}
+}
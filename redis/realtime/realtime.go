@@ -3,7 +3,10 @@ package realtime
 import (
 	"context"
 	"github.com/christopherdavenport/gocircuit"
+	"github.com/christopherdavenport/gocircuit/timeout"
 	"github.com/redis/go-redis/v9"
+	"log/slog"
+	"sync/atomic"
 	"time"
 )
 
@@ -11,17 +14,95 @@ type realtimeRedisCircuitBreakerSimple[A any] struct {
 	client   *redis.Client
 	settings CircuitBreakerSettings
 	key      string
+	instance *instance
+	classify gocircuit.Classifier[A]
+}
+
+// instance holds the state kept by this process for a circuit breaker, as opposed to the state shared in Redis.
+type instance struct {
+	key       string
+	stats     stats
+	notifier  *gocircuit.Notifier
+	events    *gocircuit.EventBus
+	checking  atomic.Bool // Whether this instance is running the health check.
+	scheduled atomic.Bool // Whether this instance has a timer starting the health check.
+}
+
+func newInstance(key string, settings CircuitBreakerSettings) *instance {
+	listeners := append([]gocircuit.StateListener{settings.OnStateChange}, settings.StateListeners...)
+	return &instance{
+		key:      key,
+		notifier: gocircuit.NewNotifier(settings.OnListenerError, listeners...),
+		events:   &gocircuit.EventBus{},
+	}
 }
 
 func (cb realtimeRedisCircuitBreakerSimple[A]) Protect(ctx context.Context, action func() (A, error)) (A, error) {
-	return protect(cb.client, ctx, cb.key, cb.settings, action)
+	return cb.ProtectContext(ctx, func(context.Context) (A, error) {
+		return action()
+	})
+}
+
+// ProtectContext is Protect for actions taking a context, which is done after Timeout if that is set.
+func (cb realtimeRedisCircuitBreakerSimple[A]) ProtectContext(ctx context.Context, action func(ctx context.Context) (A, error)) (A, error) {
+	run := func() (A, error) {
+		return action(ctx)
+	}
+	if cb.settings.Timeout > 0 {
+		run = func() (A, error) {
+			return timeout.Run(ctx, cb.settings.Timeout, action)
+		}
+	}
+	value, err := protect(cb.client, ctx, cb.key, cb.settings, cb.instance, cb.classify, gocircuit.Recover(run))
+	cb.settings.PanicMode.Rethrow(err) // Only once the panic has been recorded as a failure.
+	return value, err
+}
+
+// Stats returns the counters of this instance of the circuit breaker.
+func (cb realtimeRedisCircuitBreakerSimple[A]) Stats() Stats {
+	return cb.instance.stats.snapshot()
+}
+
+func (cb realtimeRedisCircuitBreakerSimple[A]) Force(ctx context.Context, state gocircuit.State, expiry time.Duration) error {
+	return forceState(cb.client, ctx, cb.key, cb.settings, state, expiry, cb.instance)
+}
+
+// AddStateListener registers listener for the state changes made by this instance.
+func (cb realtimeRedisCircuitBreakerSimple[A]) AddStateListener(listener gocircuit.StateListener) {
+	cb.instance.notifier.Add(listener)
+}
+
+// Subscribe returns a subscription to the activity of this instance, buffering up to buffer events.
+func (cb realtimeRedisCircuitBreakerSimple[A]) Subscribe(buffer int) *gocircuit.Subscription {
+	return cb.instance.events.Subscribe(buffer)
 }
 
-func NewRealtimeRedisCircuitBreaker[A any](client *redis.Client, key string, settings CircuitBreakerSettings) gocircuit.CircuitBreaker[A] {
+func (cb realtimeRedisCircuitBreakerSimple[A]) Inspect(ctx context.Context) (gocircuit.Snapshot, error) {
+	return Inspect(cb.client, ctx, cb.key, cb.settings)
+}
+
+func (cb realtimeRedisCircuitBreakerSimple[A]) Reset(ctx context.Context) error {
+	return reset(cb.client, ctx, cb.key, cb.settings, cb.instance)
+}
+
+// NewRealtimeRedisCircuitBreaker creates a circuit breaker sharing its state under key with every other instance
+// using the same Prefix. It also implements gocircuit.Inspector, gocircuit.Controller and StatsProvider.
+func NewRealtimeRedisCircuitBreaker[A any](client *redis.Client, key string, settings CircuitBreakerSettings) gocircuit.ContextCircuitBreaker[A] {
+	return NewRealtimeRedisCircuitBreakerWithClassifier[A](client, key, settings, nil)
+}
+
+// NewRealtimeRedisCircuitBreakerWithClassifier creates a circuit breaker deciding outcomes with classify rather than
+// IsSuccessful, so results as well as errors can count as failures.
+func NewRealtimeRedisCircuitBreakerWithClassifier[A any](client *redis.Client, key string, settings CircuitBreakerSettings, classify gocircuit.Classifier[A]) gocircuit.ContextCircuitBreaker[A] {
+	if settings.InstanceId == "" {
+		settings.InstanceId = defaultInstanceId()
+	}
 	return &realtimeRedisCircuitBreakerSimple[A]{
 		client:   client,
 		settings: settings,
 		key:      key,
+		instance: newInstance(key, settings),
+		classify: classify,
 	}
 }
 
@@ -29,18 +110,62 @@ type CircuitBreakerSettings struct {
 	Prefix          string
 	RedisKeyTimeout time.Duration // The period of time after which the state of the circuit breaker is considered stale and is reset to closed.
 
-	Interval    time.Duration // The period of time over which requests are counted.
+	Interval time.Duration // The period of time over which requests are counted.
+	// WindowSize, when positive, counts only the last WindowSize outcomes regardless of their age, kept in a capped
+	// Redis list, instead of those within Interval. The counts are always Weighted in this mode.
+	WindowSize  int
 	OpenTimeout time.Duration // The period of time after which the circuit breaker transitions from open to half-open.
 
-	ReadyToTrip   func(info Counts) bool
-	OnStateChange func(old gocircuit.State, new gocircuit.State) error
-	IsSuccessful  func(err error) bool
-}
+	ReadyToTrip func(info Counts) bool
+	// OnStateChange and StateListeners are called asynchronously, in order, after a state change made by this
+	// instance has been committed to Redis. Errors they return are passed to OnListenerError as a *gocircuit.ListenerError.
+	OnStateChange   func(old gocircuit.State, new gocircuit.State) error
+	StateListeners  []gocircuit.StateListener
+	OnListenerError func(err error)
+	// IsSuccessful decides whether an error counts as a success, unless a classifier is given. A caller canceling
+	// the context is neither a success nor a failure.
+	IsSuccessful func(err error) bool
+
+	// Weighted sums the weight of each outcome into the Weighted counts given to ReadyToTrip, kept as running sums
+	// in buckets of a tenth of Interval, so they may include outcomes up to that much older than the window.
+	// Otherwise every outcome weighs one. Weight gives the weight of an outcome from its error, unless the context
+	// sets one with gocircuit.WithWeight, and defaults to one.
+	Weighted bool
+	Weight   func(err error) float64
 
-type Counts struct {
-	Requests             int64
-	TotalSuccesses       int64
-	TotalFailures        int64
-	ConsecutiveSuccesses int64
-	ConsecutiveFailures  int64
+	SlowCallThreshold time.Duration // Actions running longer than this publish a slow call event. Zero disables it.
+	// Timeout abandons actions running longer than this with a *gocircuit.TimeoutError, counted as a failure. Zero disables it.
+	Timeout time.Duration
+
+	// HealthCheck, if set, replaces half-open probes with live traffic. Once OpenTimeout has elapsed, requests keep
+	// being rejected while one instance at a time runs HealthCheck in the background, closing the circuit breaker when
+	// it passes. Checks are started by rejected requests, and on a timer by the instance that opened the circuit
+	// breaker for as long as it stays open, at most once per HealthCheckInterval across all instances, which also
+	// bounds each check. HealthCheckInterval defaults to OpenTimeout, and at least a second.
+	HealthCheck         func(ctx context.Context) error
+	HealthCheckInterval time.Duration
+
+	// ProbeLease is how long an instance owns the half-open probe, so that only one instance probes per recovery
+	// window and a probe whose instance dies is retried once it expires. Defaults to OpenTimeout, and at least a second.
+	ProbeLease time.Duration
+
+	// MaxContentionRetries bounds how many times a request is retried after another instance changed the state
+	// first, waiting a random backoff of up to ContentionBackoff doubled each time, before failing with a
+	// *ContentionError. Zero selects 10 retries, negative disables them. ContentionBackoff defaults to a millisecond.
+	MaxContentionRetries int
+	ContentionBackoff    time.Duration
+
+	PanicMode gocircuit.PanicMode // What Protect does after a panicking action has been recorded as a failure.
+
+	AuditMaxLen int64  // The approximate number of transitions kept in the audit stream. Zero disables auditing.
+	InstanceId  string // Identifies this process in audit records. Defaults to hostname:pid.
+
+	Logger *slog.Logger // Logs transitions, rejections, retries, health check failures and failures to update Redis, by instance. Nil disables logging.
+
+	// OnStoreError is called when Redis cannot be updated after the action has run, leaving its outcome unrecorded.
+	OnStoreError func(operation string, err error)
+	// StrictStoreErrors joins a *StoreError to the error returned from Protect when the outcome could not be recorded.
+	StrictStoreErrors bool
 }
+
+type Counts = gocircuit.Counts
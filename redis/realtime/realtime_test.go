@@ -0,0 +1,54 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/christopherdavenport/gocircuit"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestClient returns a client of a fresh miniredis server, closed with the test.
+func newTestClient(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return server, client
+}
+
+func testSettings() CircuitBreakerSettings {
+	return CircuitBreakerSettings{
+		Prefix:          "test",
+		RedisKeyTimeout: time.Hour,
+		Interval:        time.Minute,
+		OpenTimeout:     time.Minute,
+		InstanceId:      "test",
+		ReadyToTrip: func(counts Counts) bool {
+			return counts.ConsecutiveFailures >= 3
+		},
+	}
+}
+
+// trip makes the fourth, tripping request through cb after three failures.
+func trip(cb gocircuit.CircuitBreaker[int]) {
+	for i := 0; i < 4; i++ {
+		_, _ = cb.Protect(context.Background(), func() (int, error) { return 0, errors.New("failed") })
+	}
+}
+
+func TestRejectionIsSentinel(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	cb := NewRealtimeRedisCircuitBreaker[int](client, "rejection", testSettings())
+	if err := cb.(gocircuit.Controller).Force(ctx, gocircuit.StateForcedOpen, 0); err != nil {
+		t.Fatal(err)
+	}
+	_, err := cb.Protect(ctx, func() (int, error) { return 0, nil })
+	if err != CircuitBreakerOpen || !errors.Is(err, gocircuit.ErrOpen) {
+		t.Errorf("expected CircuitBreakerOpen matching gocircuit.ErrOpen, got %v", err)
+	}
+}
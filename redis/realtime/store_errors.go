@@ -0,0 +1,89 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// StoreError reports that Redis could not be updated after the action had already run,
+// so its outcome is missing from the window.
+type StoreError struct {
+	Operation string
+	Err       error
+}
+
+func (e *StoreError) Error() string {
+	return fmt.Sprintf("circuit breaker failed to %s: %s", e.Operation, e.Err)
+}
+
+func (e *StoreError) Unwrap() error {
+	return e.Err
+}
+
+// Stats are counters of problems a circuit breaker instance has run into.
+type Stats struct {
+	LostOutcomes       int64 // Outcomes of actions that could not be recorded in Redis.
+	Contentions        int64 // Attempts to change the state that lost to another instance and were retried or given up.
+	ContentionFailures int64 // Requests that failed with a *ContentionError.
+	// BookkeepingErrors are failures to update Redis that lost no outcome, such as releasing a lease or closing
+	// after a health check, which are logged but not passed to OnStoreError.
+	BookkeepingErrors int64
+}
+
+// StatsProvider is implemented by the circuit breakers created by this package, so their Stats can be read
+// from the gocircuit.ContextCircuitBreaker returned by NewRealtimeRedisCircuitBreaker.
+type StatsProvider interface {
+	Stats() Stats
+}
+
+type stats struct {
+	lostOutcomes       atomic.Int64
+	contentions        atomic.Int64
+	contentionFailures atomic.Int64
+	bookkeepingErrors  atomic.Int64
+}
+
+func (s *stats) snapshot() Stats {
+	return Stats{
+		LostOutcomes:       s.lostOutcomes.Load(),
+		Contentions:        s.contentions.Load(),
+		ContentionFailures: s.contentionFailures.Load(),
+		BookkeepingErrors:  s.bookkeepingErrors.Load(),
+	}
+}
+
+// storeFailed handles an error updating Redis after the action has run. It returns the error to
+// surface to the caller, which is nil unless StrictStoreErrors is set.
+func storeFailed(ctx context.Context, key string, settings CircuitBreakerSettings, stats *stats, operation string, err error) error {
+	if err == nil {
+		return nil
+	}
+	stats.lostOutcomes.Add(1)
+	logStoreError(ctx, key, settings, operation, err)
+	if settings.OnStoreError != nil {
+		settings.OnStoreError(operation, err)
+	}
+	if settings.StrictStoreErrors {
+		return &StoreError{Operation: operation, Err: err}
+	}
+	return nil
+}
+
+// bookkeepingFailed handles an error updating Redis that lost no outcome, which only delays recovery.
+func bookkeepingFailed(ctx context.Context, key string, settings CircuitBreakerSettings, stats *stats, operation string, err error) {
+	if err == nil {
+		return
+	}
+	stats.bookkeepingErrors.Add(1)
+	logStoreError(ctx, key, settings, operation, err)
+}
+
+// withStoreError joins storeErr to the error returned by the action, leaving actionErr untouched if there is none.
+func withStoreError(actionErr error, storeErr error) error {
+	if storeErr == nil {
+		return actionErr
+	}
+	return errors.Join(actionErr, storeErr)
+}
@@ -0,0 +1,40 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStoreErrorLosesOutcome(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		server, client := newTestClient(t)
+		settings := testSettings()
+		settings.StrictStoreErrors = strict
+		var operations []string
+		settings.OnStoreError = func(operation string, err error) { operations = append(operations, operation) }
+		cb := NewRealtimeRedisCircuitBreaker[int](client, "store", settings)
+
+		actionErr := errors.New("failed")
+		_, err := cb.Protect(context.Background(), func() (int, error) {
+			server.Close() // Redis goes away while the action runs, so its failure cannot be recorded.
+			return 0, actionErr
+		})
+
+		if !errors.Is(err, actionErr) {
+			t.Errorf("strict %t: expected the action error, got %v", strict, err)
+		}
+		var storeErr *StoreError
+		if errors.As(err, &storeErr) != strict {
+			t.Errorf("strict %t: expected a *StoreError only if strict, got %v", strict, err)
+		} else if strict && storeErr.Operation != "register failure" {
+			t.Errorf("expected the failure to be lost, got %q", storeErr.Operation)
+		}
+		if len(operations) != 1 || operations[0] != "register failure" {
+			t.Errorf("strict %t: expected OnStoreError for the failure, got %v", strict, operations)
+		}
+		if lost := cb.(StatsProvider).Stats().LostOutcomes; lost != 1 {
+			t.Errorf("strict %t: expected 1 lost outcome, got %d", strict, lost)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+	"github.com/redis/go-redis/v9"
+)
+
+// weightBuckets is the number of time buckets the weight sums of a window are kept in, so the weighted counts
+// may include outcomes up to Interval/weightBuckets older than the window.
+const weightBuckets = 10
+
+// The fields of a weight bucket, in the order of the gocircuit.WeightedCounts fields.
+var weightFields = []string{"requests", "successes", "failures", "consecutiveSuccesses", "consecutiveFailures"}
+
+func weightsKey(prefix string, key string) string {
+	return fmt.Sprintf("%s:weights:%s", prefix, key)
+}
+
+// weightBucketKey returns the key of the hash holding the weight sums of the outcomes recorded in bucket.
+func weightBucketKey(prefix string, key string, bucket int64) string {
+	return fmt.Sprintf("%s:%d", weightsKey(prefix, key), bucket)
+}
+
+func weightBucketWidth(settings CircuitBreakerSettings) int64 {
+	return max(settings.Interval.Nanoseconds()/weightBuckets, 1)
+}
+
+// windowBuckets returns the keys of the weight buckets holding outcomes within the window ending at systime.
+func windowBuckets(key string, settings CircuitBreakerSettings, systime time.Time) []string {
+	width := weightBucketWidth(settings)
+	last := systime.UnixNano() / width
+	first := (systime.UnixNano() - settings.Interval.Nanoseconds()) / width
+	keys := make([]string, 0, last-first+1)
+	for bucket := first; bucket <= last; bucket++ {
+		keys = append(keys, weightBucketKey(settings.Prefix, key, bucket))
+	}
+	return keys
+}
+
+// addWeight queues adding weight to the running sums of the bucket of systime in pipe, ending the consecutive
+// run of the other outcome across the window. The bucket expires once it has left the window.
+func addWeight(pipe redis.Pipeliner, ctx context.Context, key string, settings CircuitBreakerSettings, systime time.Time, success bool, weight float64) {
+	total, consecutive, other := weightFields[2], weightFields[4], weightFields[3]
+	if success {
+		total, consecutive, other = weightFields[1], weightFields[3], weightFields[4]
+	}
+	for _, bucket := range windowBuckets(key, settings, systime) {
+		pipe.HDel(ctx, bucket, other)
+	}
+	width := weightBucketWidth(settings)
+	bucket := weightBucketKey(settings.Prefix, key, systime.UnixNano()/width)
+	pipe.HIncrByFloat(ctx, bucket, weightFields[0], weight)
+	pipe.HIncrByFloat(ctx, bucket, total, weight)
+	pipe.HIncrByFloat(ctx, bucket, consecutive, weight)
+	pipe.PExpire(ctx, bucket, settings.Interval+2*time.Duration(width))
+}
+
+// readWeights queues reading the weight buckets of the window ending at systime in pipe.
+func readWeights(pipe redis.Pipeliner, ctx context.Context, key string, settings CircuitBreakerSettings, systime time.Time) []*redis.SliceCmd {
+	buckets := windowBuckets(key, settings, systime)
+	cmds := make([]*redis.SliceCmd, len(buckets))
+	for i, bucket := range buckets {
+		cmds[i] = pipe.HMGet(ctx, bucket, weightFields...)
+	}
+	return cmds
+}
+
+// sumWeights sums the buckets read by readWeights.
+func sumWeights(cmds []*redis.SliceCmd) (*gocircuit.WeightedCounts, error) {
+	var sums [5]float64
+	for _, cmd := range cmds {
+		values, err := cmd.Result()
+		if err != nil {
+			return nil, err
+		}
+		for i, value := range values {
+			if value == nil {
+				continue // The bucket or field does not exist.
+			}
+			s, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("unexpected weight sum: %v", value)
+			}
+			sum, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, err
+			}
+			sums[i] += sum
+		}
+	}
+	return &gocircuit.WeightedCounts{
+		Requests:             sums[0],
+		TotalSuccesses:       sums[1],
+		TotalFailures:        sums[2],
+		ConsecutiveSuccesses: sums[3],
+		ConsecutiveFailures:  sums[4],
+	}, nil
+}
+
+// queueClearWeights queues deleting the weight buckets of the window ending at systime in pipe.
+func queueClearWeights(pipe redis.Pipeliner, ctx context.Context, key string, settings CircuitBreakerSettings, systime time.Time) {
+	for _, bucket := range windowBuckets(key, settings, systime) {
+		pipe.Del(ctx, bucket)
+	}
+}
+
+func weightedCountsFromStrings(sums []string) (*gocircuit.WeightedCounts, error) {
+	if len(sums) != 5 {
+		return nil, fmt.Errorf("expected 5 weight sums, got %d", len(sums))
+	}
+	values := make([]float64, len(sums))
+	for i, sum := range sums {
+		value, err := strconv.ParseFloat(sum, 64)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return &gocircuit.WeightedCounts{
+		Requests:             values[0],
+		TotalSuccesses:       values[1],
+		TotalFailures:        values[2],
+		ConsecutiveSuccesses: values[3],
+		ConsecutiveFailures:  values[4],
+	}, nil
+}
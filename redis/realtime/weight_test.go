@@ -0,0 +1,48 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+func TestWeightedCounts(t *testing.T) {
+	_, client := newTestClient(t)
+	ctx := context.Background()
+	settings := testSettings()
+	settings.Weighted = true
+	settings.ReadyToTrip = func(Counts) bool { return false }
+	cb := NewRealtimeRedisCircuitBreaker[int](client, "weighted", settings)
+	for _, outcome := range []struct {
+		weight float64
+		err    error
+	}{
+		{0.5, nil},
+		{2, errors.New("failed")},
+		{1.5, errors.New("failed")},
+	} {
+		_, _ = cb.Protect(gocircuit.WithWeight(ctx, outcome.weight), func() (int, error) { return 0, outcome.err })
+	}
+
+	snapshot, err := Inspect(client, ctx, "weighted", settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := gocircuit.WeightedCounts{Requests: 4, TotalSuccesses: 0.5, TotalFailures: 3.5, ConsecutiveFailures: 3.5}
+	if snapshot.Counts.Weighted != want {
+		t.Errorf("expected %+v, got %+v", want, snapshot.Counts.Weighted)
+	}
+
+	if err := cb.(gocircuit.Controller).Reset(ctx); err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err = Inspect(client, ctx, "weighted", settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Counts.Weighted != (gocircuit.WeightedCounts{}) {
+		t.Errorf("expected Reset to clear the weights, got %+v", snapshot.Counts.Weighted)
+	}
+}
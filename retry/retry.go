@@ -0,0 +1,152 @@
+// Package retry retries actions protected by a circuit breaker, without retrying into an open circuit.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+// Settings configures the retries made by New. Zero values select the defaults.
+type Settings[A any] struct {
+	MaxAttempts    int           // The number of attempts, including the first. Defaults to 3.
+	InitialBackoff time.Duration // The wait before the second attempt. Defaults to 100 milliseconds.
+	MaxBackoff     time.Duration // The longest wait between attempts. Defaults to 10 seconds.
+	Multiplier     float64       // How much the wait grows after each attempt. Defaults to 2.
+	Jitter         float64       // The fraction of each wait that is randomized, from 0 to 1.
+
+	// Retryable decides which results are retried: only those it classifies as failures. Defaults to any error
+	// other than the caller canceling the context. A panic is never retried.
+	Retryable gocircuit.Classifier[A]
+	// HonorRetryAfter waits out the retry-after of a rejection, by an open circuit breaker or a rate limit,
+	// if it has one and it ends before the context, instead of giving up immediately. A rejection without one
+	// waits until the OpenUntil of the circuit breaker, if it is a gocircuit.Inspector reporting it.
+	HonorRetryAfter bool
+
+	Rand func() float64 // The source of jitter in [0, 1). Defaults to math/rand/v2.
+}
+
+type retrying[A any] struct {
+	circuit  gocircuit.CircuitBreaker[A]
+	settings Settings[A]
+}
+
+// New returns a circuit breaker retrying actions protected by circuit according to settings.
+// It gives up as soon as circuit rejects an action, unless HonorRetryAfter is set. A rejection is an error matching
+// gocircuit.ErrOpen, one suggesting when to try again, or any other error circuit returns without running the action.
+//
+// A nil circuit retries the action alone, for use as a policy in gocircuit.Chain.
+func New[A any](circuit gocircuit.CircuitBreaker[A], settings Settings[A]) gocircuit.ContextCircuitBreaker[A] {
+	if settings.MaxAttempts <= 0 {
+		settings.MaxAttempts = 3
+	}
+	if settings.InitialBackoff <= 0 {
+		settings.InitialBackoff = 100 * time.Millisecond
+	}
+	if settings.MaxBackoff <= 0 {
+		settings.MaxBackoff = 10 * time.Second
+	}
+	if settings.Multiplier < 1 {
+		settings.Multiplier = 2
+	}
+	if settings.Rand == nil {
+		settings.Rand = rand.Float64
+	}
+	return &retrying[A]{circuit: circuit, settings: settings}
+}
+
+func (r *retrying[A]) Protect(ctx context.Context, action func() (A, error)) (A, error) {
+	return r.ProtectContext(ctx, func(context.Context) (A, error) {
+		return action()
+	})
+}
+
+func (r *retrying[A]) ProtectContext(ctx context.Context, action func(ctx context.Context) (A, error)) (A, error) {
+	backoff := r.settings.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		var value A
+		var err error
+		var rejected bool
+		if r.circuit == nil {
+			value, err = action(ctx)
+		} else {
+			value, err, rejected = gocircuit.ProtectObserved(r.circuit, ctx, action)
+		}
+		rejected = rejected || rejection(err)
+		if attempt == r.settings.MaxAttempts || !(rejected || r.retryable(ctx, value, err)) {
+			return value, err
+		}
+
+		wait := r.jitter(backoff)
+		backoff = min(time.Duration(float64(backoff)*r.settings.Multiplier), r.settings.MaxBackoff)
+		if rejected {
+			retryAfter, ok := r.retryAfter(ctx, err)
+			if !r.settings.HonorRetryAfter || !ok {
+				return value, err
+			}
+			if deadline, ok := ctx.Deadline(); ok && time.Now().Add(retryAfter).After(deadline) {
+				return value, err
+			}
+			wait = retryAfter
+		}
+		if sleep(ctx, wait) != nil {
+			return value, err
+		}
+	}
+}
+
+func (r *retrying[A]) retryable(ctx context.Context, value A, err error) bool {
+	var panicErr *gocircuit.PanicError
+	if errors.As(err, &panicErr) {
+		return false
+	}
+	return gocircuit.Classify(ctx, r.settings.Retryable, nil, value, err) == gocircuit.OutcomeFailure
+}
+
+// rejection reports whether err is a rejection by an open circuit breaker or one suggesting when to try again, such as a rate limit.
+func rejection(err error) bool {
+	_, ok := gocircuit.RetryAfter(err)
+	return ok || errors.Is(err, gocircuit.ErrOpen)
+}
+
+// retryAfter returns how long until a rejection with err may be retried, from err itself or else from the circuit
+// breaker, and whether that is known.
+func (r *retrying[A]) retryAfter(ctx context.Context, err error) (time.Duration, bool) {
+	if retryAfter, ok := gocircuit.RetryAfter(err); ok {
+		return retryAfter, true
+	}
+	inspector, ok := gocircuit.AsInspector(r.circuit)
+	if !ok {
+		return 0, false
+	}
+	snapshot, inspectErr := inspector.Inspect(ctx)
+	if inspectErr != nil || snapshot.OpenUntil.IsZero() {
+		return 0, false
+	}
+	retryAfter := time.Until(snapshot.OpenUntil)
+	return retryAfter, retryAfter > 0
+}
+
+func (r *retrying[A]) jitter(wait time.Duration) time.Duration {
+	jitter := min(max(r.settings.Jitter, 0), 1)
+	return time.Duration(float64(wait) * (1 - jitter + jitter*r.settings.Rand()))
+}
+
+func (r *retrying[A]) Unwrap() any {
+	return r.circuit
+}
+
+// sleep waits for d, or returns the error of ctx if it is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,80 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+// fakeBreaker rejects the first rejections calls with rejection, then runs the action.
+type fakeBreaker struct {
+	rejection  error
+	rejections int
+	openUntil  time.Time
+	calls      int
+}
+
+func (f *fakeBreaker) Protect(ctx context.Context, action func() (int, error)) (int, error) {
+	f.calls++
+	if f.calls <= f.rejections {
+		return 0, f.rejection
+	}
+	return action()
+}
+
+func (f *fakeBreaker) Inspect(ctx context.Context) (gocircuit.Snapshot, error) {
+	return gocircuit.Snapshot{State: gocircuit.StateOpen, OpenUntil: f.openUntil}, nil
+}
+
+var errFailed = errors.New("failed")
+
+func TestRetriesFailures(t *testing.T) {
+	attempts := 0
+	r := New[int](nil, Settings[int]{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+	_, err := r.Protect(context.Background(), func() (int, error) {
+		attempts++
+		return 0, errFailed
+	})
+	if !errors.Is(err, errFailed) || attempts != 3 {
+		t.Errorf("expected 3 failed attempts, got %d and %v", attempts, err)
+	}
+}
+
+func TestStopsOnRejectionWithoutRunning(t *testing.T) {
+	rejection := errors.New("rejected") // Not matching gocircuit.ErrOpen, as a breaker outside this module may return.
+	breaker := &fakeBreaker{rejection: rejection, rejections: 3}
+	r := New[int](breaker, Settings[int]{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+	_, err := r.Protect(context.Background(), func() (int, error) { return 1, nil })
+	if err != rejection || breaker.calls != 1 {
+		t.Errorf("expected to give up on the first rejection, got %d calls and %v", breaker.calls, err)
+	}
+}
+
+func TestHonorRetryAfterFromInspector(t *testing.T) {
+	breaker := &fakeBreaker{rejection: gocircuit.ErrOpen, rejections: 1, openUntil: time.Now().Add(20 * time.Millisecond)}
+	r := New[int](breaker, Settings[int]{MaxAttempts: 2, InitialBackoff: time.Millisecond, HonorRetryAfter: true})
+	start := time.Now()
+	value, err := r.Protect(context.Background(), func() (int, error) { return 1, nil })
+	if err != nil || value != 1 {
+		t.Fatalf("expected the second attempt to succeed, got %d and %v", value, err)
+	}
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("expected to wait until the breaker admits calls, waited %s", elapsed)
+	}
+}
+
+func TestNeverRetriesPanics(t *testing.T) {
+	attempts := 0
+	r := New[int](nil, Settings[int]{MaxAttempts: 3, InitialBackoff: time.Millisecond})
+	_, err := r.Protect(context.Background(), gocircuit.Recover(func() (int, error) {
+		attempts++
+		panic("boom")
+	}))
+	var panicErr *gocircuit.PanicError
+	if !errors.As(err, &panicErr) || attempts != 1 {
+		t.Errorf("expected a single panicking attempt, got %d and %v", attempts, err)
+	}
+}
@@ -0,0 +1,136 @@
+// Slogcircuit logs circuit breaker activity with log/slog.
+
+package slogcircuit
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+// Attribute keys shared by every gocircuit log record.
+const (
+	BreakerKey = "breaker"
+	StateKey   = "state"
+	FromKey    = "from"
+	ToKey      = "to"
+	ReasonKey  = "reason"
+	ErrorKey   = "error"
+
+	InstanceKey  = "instance"  // The instance of a circuit breaker sharing its state with others.
+	OperationKey = "operation" // The update of the shared state that failed.
+	AttemptKey   = "attempt"   // The attempt at changing contended state.
+)
+
+// Messages shared by every gocircuit log record.
+const (
+	MessageTransition = "circuit breaker state changed"
+	MessageRejected   = "circuit breaker rejected request"
+	MessageFailure    = "circuit breaker recorded failure"
+)
+
+// LogTransition logs a state change at info level.
+func LogTransition(ctx context.Context, logger *slog.Logger, breaker string, from gocircuit.State, to gocircuit.State, reason string, attrs ...slog.Attr) {
+	base := []slog.Attr{
+		slog.String(BreakerKey, breaker),
+		slog.String(FromKey, from.String()),
+		slog.String(ToKey, to.String()),
+	}
+	if reason != "" {
+		base = append(base, slog.String(ReasonKey, reason))
+	}
+	attrs = append(base, attrs...)
+	logger.LogAttrs(ctx, slog.LevelInfo, MessageTransition, attrs...)
+}
+
+// LogRejected logs a rejected request at debug level.
+func LogRejected(ctx context.Context, logger *slog.Logger, breaker string, err error, attrs ...slog.Attr) {
+	attrs = append([]slog.Attr{
+		slog.String(BreakerKey, breaker),
+		slog.Any(ErrorKey, err),
+	}, attrs...)
+	logger.LogAttrs(ctx, slog.LevelDebug, MessageRejected, attrs...)
+}
+
+// eventBuffer is the number of undelivered events buffered for the logged circuit breaker.
+const eventBuffer = 1024
+
+type logged[A any] struct {
+	name    string
+	circuit gocircuit.CircuitBreaker[A]
+	logger  *slog.Logger
+	events  bool // Whether requests are logged from the events of circuit.
+}
+
+// New returns a circuit breaker logging the rejections and failures of circuit to logger under name.
+//
+// If circuit is a gocircuit.EventSource publishing events, as the circuit breakers in this module do, its
+// rejections, failures and transitions are logged from its events, so only the errors it counted as failures
+// are logged as such. They are logged asynchronously, without the context of the request.
+//
+// Otherwise rejections are logged as they return, and every error other than the caller canceling the context
+// as a failure. Pass StateChange(logger, name) as the OnStateChange setting of circuit to also log its transitions.
+func New[A any](name string, circuit gocircuit.CircuitBreaker[A], logger *slog.Logger) gocircuit.CircuitBreaker[A] {
+	l := &logged[A]{
+		name:    name,
+		circuit: circuit,
+		logger:  logger,
+	}
+	if source, ok := gocircuit.AsEventSource(circuit); ok {
+		subscription := source.Subscribe(eventBuffer)
+		if !subscription.Closed() {
+			l.events = true
+			go func() {
+				for event := range subscription.Events() {
+					l.event(event)
+				}
+			}()
+		}
+	}
+	return l
+}
+
+// event logs an event of the wrapped circuit breaker.
+func (l *logged[A]) event(event gocircuit.Event) {
+	ctx := context.Background()
+	switch event.Kind {
+	case gocircuit.EventRejected:
+		LogRejected(ctx, l.logger, l.name, event.Err, slog.String(StateKey, event.State.String()))
+	case gocircuit.EventFailure:
+		logFailure(ctx, l.logger, l.name, event.Err)
+	case gocircuit.EventStateChange:
+		LogTransition(ctx, l.logger, l.name, event.From, event.State, "")
+	}
+}
+
+func logFailure(ctx context.Context, logger *slog.Logger, breaker string, err error) {
+	logger.LogAttrs(ctx, slog.LevelDebug, MessageFailure, slog.String(BreakerKey, breaker), slog.Any(ErrorKey, err))
+}
+
+// StateChange returns a function suitable for the OnStateChange setting of the named breaker which logs its
+// transitions, for a breaker publishing no events.
+func StateChange(logger *slog.Logger, name string) func(old gocircuit.State, new gocircuit.State) error {
+	return func(old gocircuit.State, new gocircuit.State) error {
+		LogTransition(context.Background(), logger, name, old, new, "")
+		return nil
+	}
+}
+
+func (l *logged[A]) Protect(ctx context.Context, action func() (A, error)) (A, error) {
+	value, err, rejected := gocircuit.ProtectObserved(l.circuit, ctx, func(context.Context) (A, error) {
+		return action()
+	})
+	switch {
+	case l.events: // Logged from the events of the circuit breaker instead.
+	case rejected:
+		LogRejected(ctx, l.logger, l.name, err)
+	case gocircuit.Classify(ctx, nil, nil, value, err) == gocircuit.OutcomeFailure:
+		logFailure(ctx, l.logger, l.name, err)
+	}
+	return value, err
+}
+
+func (l *logged[A]) Unwrap() any {
+	return l.circuit
+}
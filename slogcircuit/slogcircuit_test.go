@@ -0,0 +1,109 @@
+package slogcircuit
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+	"github.com/christopherdavenport/gocircuit/memory"
+	"github.com/christopherdavenport/gocircuit/noop"
+)
+
+// recorder is a slog.Handler keeping every record it handles, by message, with its attributes.
+type recorder struct {
+	mu      sync.Mutex
+	records []record
+}
+
+type record struct {
+	message string
+	attrs   map[string]string
+}
+
+func (r *recorder) Enabled(context.Context, slog.Level) bool { return true }
+
+func (r *recorder) Handle(_ context.Context, rec slog.Record) error {
+	attrs := map[string]string{}
+	rec.Attrs(func(attr slog.Attr) bool {
+		attrs[attr.Key] = attr.Value.String()
+		return true
+	})
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, record{message: rec.Message, attrs: attrs})
+	return nil
+}
+
+func (r *recorder) WithAttrs([]slog.Attr) slog.Handler { return r }
+func (r *recorder) WithGroup(string) slog.Handler      { return r }
+
+// wait waits for n records to be logged, and returns them.
+func (r *recorder) wait(t *testing.T, n int) []record {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		r.mu.Lock()
+		records := append([]record(nil), r.records...)
+		r.mu.Unlock()
+		if len(records) >= n || time.Now().After(deadline) {
+			if len(records) != n {
+				t.Fatalf("expected %d records, got %+v", n, records)
+			}
+			return records
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLogsAsBreakerCounts(t *testing.T) {
+	logs := &recorder{}
+	circuit := memory.NewMemoryCircuitBreaker[int]("test", memory.Settings{
+		IsSuccessful: func(err error) bool { return err.Error() == "not found" },
+		ReadyToTrip:  func(counts gocircuit.Counts) bool { return counts.TotalFailures > 0 },
+	})
+	breaker := New("test", circuit, slog.New(logs))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _ = breaker.Protect(context.Background(), func() (int, error) { return 0, errors.New("not found") })
+	_, _ = breaker.Protect(ctx, func() (int, error) { return 0, ctx.Err() })
+	_, _ = breaker.Protect(context.Background(), func() (int, error) { return 0, errors.New("failed") }) // Trips.
+	_, _ = breaker.Protect(context.Background(), func() (int, error) { return 1, nil })
+
+	records := logs.wait(t, 3)
+	expected := []record{
+		{message: MessageFailure, attrs: map[string]string{BreakerKey: "test", ErrorKey: "failed"}},
+		{message: MessageTransition, attrs: map[string]string{BreakerKey: "test", FromKey: "closed", ToKey: "open"}},
+		{message: MessageRejected, attrs: map[string]string{BreakerKey: "test", StateKey: "open"}},
+	}
+	for i, e := range expected {
+		if records[i].message != e.message {
+			t.Fatalf("expected record %d to be %q, got %+v", i, e.message, records)
+		}
+		for key, value := range e.attrs {
+			if records[i].attrs[key] != value {
+				t.Errorf("expected %s of %q to be %q, got %q", key, e.message, value, records[i].attrs[key])
+			}
+		}
+	}
+}
+
+func TestLogsEachCallWithoutEvents(t *testing.T) {
+	logs := &recorder{}
+	breaker := New[int]("noop", noop.NoopCircuitBreaker[int]{}, slog.New(logs)) // Publishes no events.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _ = breaker.Protect(context.Background(), func() (int, error) { return 1, nil })
+	_, _ = breaker.Protect(ctx, func() (int, error) { return 0, ctx.Err() })
+	_, _ = breaker.Protect(context.Background(), func() (int, error) { return 0, errors.New("failed") })
+
+	records := logs.wait(t, 1)
+	if records[0].message != MessageFailure || records[0].attrs[ErrorKey] != "failed" || records[0].attrs[BreakerKey] != "noop" {
+		t.Errorf("expected the failure to be logged, got %+v", records[0])
+	}
+}
@@ -12,8 +12,13 @@ const (
 	StateClosed State = iota
 	StateHalfOpen
 	StateOpen
+	StateForcedOpen   // Pinned open by an operator, every request is rejected.
+	StateForcedClosed // Pinned closed by an operator, requests are neither rejected nor counted.
 )
 
+// StateDisabled is an alias of StateForcedClosed.
+const StateDisabled = StateForcedClosed
+
 func StateFromString(s string) (State, error) {
 	switch s {
 	case "closed":
@@ -22,6 +27,10 @@ func StateFromString(s string) (State, error) {
 		return StateHalfOpen, nil
 	case "open":
 		return StateOpen, nil
+	case "forced-open":
+		return StateForcedOpen, nil
+	case "forced-closed", "disabled":
+		return StateForcedClosed, nil
 	default:
 		return StateClosed, fmt.Errorf("unknown state: %s", s)
 	}
@@ -35,11 +44,20 @@ func StateToString(s State) (string, error) {
 		return "half", nil
 	case StateOpen:
 		return "open", nil
+	case StateForcedOpen:
+		return "forced-open", nil
+	case StateForcedClosed:
+		return "forced-closed", nil
 	default:
 		return "closed", fmt.Errorf("unknown state: %d", s)
 	}
 }
 
+// IsForced reports whether the state was set by an operator rather than by the circuit breaker itself.
+func (s State) IsForced() bool {
+	return s == StateForcedOpen || s == StateForcedClosed
+}
+
 func (s State) String() string {
 	stateString, err := StateToString(s)
 	if err != nil {
@@ -47,3 +65,20 @@ func (s State) String() string {
 	}
 	return stateString
 }
+
+func (s State) MarshalText() ([]byte, error) {
+	stateString, err := StateToString(s)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(stateString), nil
+}
+
+func (s *State) UnmarshalText(text []byte) error {
+	state, err := StateFromString(string(text))
+	if err != nil {
+		return err
+	}
+	*s = state
+	return nil
+}
@@ -0,0 +1,77 @@
+// Package timeout bounds how long protected actions run, so that hung dependencies count as failures.
+package timeout
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+type timeout[A any] struct {
+	circuit gocircuit.CircuitBreaker[A]
+	timeout time.Duration
+}
+
+// New returns a circuit breaker abandoning actions protected by circuit after d with a *gocircuit.TimeoutError,
+// which circuit counts as a failure. Actions given a context by ProtectContext should stop once it is done.
+//
+// A nil circuit bounds the action alone, for use as a policy in gocircuit.Chain inside a circuit breaker.
+func New[A any](circuit gocircuit.CircuitBreaker[A], d time.Duration) gocircuit.ContextCircuitBreaker[A] {
+	return &timeout[A]{circuit: circuit, timeout: d}
+}
+
+func (t *timeout[A]) Protect(ctx context.Context, action func() (A, error)) (A, error) {
+	return t.ProtectContext(ctx, func(context.Context) (A, error) {
+		return action()
+	})
+}
+
+func (t *timeout[A]) ProtectContext(ctx context.Context, action func(ctx context.Context) (A, error)) (A, error) {
+	bounded := func(ctx context.Context) (A, error) {
+		return Run(ctx, t.timeout, action)
+	}
+	if t.circuit == nil {
+		return bounded(ctx)
+	}
+	return gocircuit.ProtectContext(t.circuit, ctx, bounded)
+}
+
+func (t *timeout[A]) Unwrap() any {
+	return t.circuit
+}
+
+type result[A any] struct {
+	value A
+	err   error
+}
+
+// Run runs action with a context derived from ctx that is done after d, returning a *gocircuit.TimeoutError
+// if it is still running by then. The action is left to finish in the background, its result discarded.
+// A panic in the action is raised again in the caller.
+func Run[A any](ctx context.Context, d time.Duration, action func(ctx context.Context) (A, error)) (A, error) {
+	bounded, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+
+	done := make(chan result[A], 1)
+	go func() {
+		value, err := gocircuit.Recover(func() (A, error) { return action(bounded) })()
+		done <- result[A]{value: value, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		gocircuit.PanicRepanic.Rethrow(r.err)
+		if errors.Is(r.err, context.DeadlineExceeded) && bounded.Err() != nil && ctx.Err() == nil {
+			return r.value, &gocircuit.TimeoutError{Timeout: d} // The action gave up on the bounded context.
+		}
+		return r.value, r.err
+	case <-bounded.Done():
+		var empty A
+		if ctx.Err() != nil {
+			return empty, ctx.Err()
+		}
+		return empty, &gocircuit.TimeoutError{Timeout: d}
+	}
+}
@@ -0,0 +1,53 @@
+package timeout
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/christopherdavenport/gocircuit"
+)
+
+func TestRunTimesOut(t *testing.T) {
+	canceled := make(chan struct{})
+	_, err := Run(context.Background(), 10*time.Millisecond, func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		close(canceled)
+		time.Sleep(10 * time.Millisecond) // Outlives Run.
+		return 1, nil
+	})
+	var timeoutErr *gocircuit.TimeoutError
+	if !errors.As(err, &timeoutErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a TimeoutError matching context.DeadlineExceeded, got %v", err)
+	}
+	<-canceled
+}
+
+func TestRunReturnsResult(t *testing.T) {
+	value, err := Run(context.Background(), time.Second, func(ctx context.Context) (int, error) { return 1, nil })
+	if value != 1 || err != nil {
+		t.Errorf("expected the result of the action, got %d and %v", value, err)
+	}
+}
+
+func TestRunCallerCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Run(ctx, time.Second, func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		return 0, ctx.Err()
+	})
+	if err != context.Canceled {
+		t.Errorf("expected the caller's cancellation rather than a timeout, got %v", err)
+	}
+}
+
+func TestRunRepanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected the panic of the action in the caller")
+		}
+	}()
+	_, _ = Run(context.Background(), time.Second, func(ctx context.Context) (int, error) { panic("boom") })
+}
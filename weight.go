@@ -0,0 +1,48 @@
+package gocircuit
+
+import (
+	"context"
+)
+
+// WeightedCounts are Counts summing the weight of each outcome instead of counting it as one,
+// so that trip policies can treat some failures as worse than others.
+type WeightedCounts struct {
+	Requests             float64
+	TotalSuccesses       float64
+	TotalFailures        float64
+	ConsecutiveSuccesses float64
+	ConsecutiveFailures  float64
+}
+
+// WithUnitWeights returns c with Weighted set as if every outcome weighed one,
+// for implementations that do not record weights.
+func (c Counts) WithUnitWeights() Counts {
+	c.Weighted = WeightedCounts{
+		Requests:             float64(c.Requests),
+		TotalSuccesses:       float64(c.TotalSuccesses),
+		TotalFailures:        float64(c.TotalFailures),
+		ConsecutiveSuccesses: float64(c.ConsecutiveSuccesses),
+		ConsecutiveFailures:  float64(c.ConsecutiveFailures),
+	}
+	return c
+}
+
+type weightKey struct{}
+
+// WithWeight returns a context making the outcome of the action protected with it weigh weight,
+// overriding the weight the circuit breaker would otherwise give it.
+func WithWeight(ctx context.Context, weight float64) context.Context {
+	return context.WithValue(ctx, weightKey{}, weight)
+}
+
+// Weight returns the weight of an outcome: the one set on ctx by WithWeight, otherwise the one weigh
+// gives err if it is not nil, otherwise one.
+func Weight(ctx context.Context, weigh func(err error) float64, err error) float64 {
+	if weight, ok := ctx.Value(weightKey{}).(float64); ok {
+		return weight
+	}
+	if weigh != nil {
+		return weigh(err)
+	}
+	return 1
+}